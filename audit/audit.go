@@ -0,0 +1,102 @@
+// Package audit writes a JSON-lines trail of configuration access and changes.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+// Event types recorded in the audit log
+const (
+	EventConfigLoad     = "config_load"
+	EventReload         = "config_reload"
+	EventSecretResolved = "secret_resolved"
+)
+
+// Outcomes of an audited action
+const (
+	OutcomeSuccess  = "success"
+	OutcomeFailure  = "failure"
+	OutcomeAccepted = "accepted"
+	OutcomeRejected = "rejected"
+)
+
+// Event is a single audit record. Secret values are never recorded, only names.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Outcome string    `json:"outcome,omitempty"`
+	Source  string    `json:"source,omitempty"`
+	Actor   string    `json:"actor,omitempty"`
+	Hash    string    `json:"hash,omitempty"`
+	Keys    []string  `json:"keys,omitempty"`
+	Secret  string    `json:"secret,omitempty"`
+	Reasons []string  `json:"reasons,omitempty"`
+}
+
+// Logger appends events as JSON lines to a sink. A nil *Logger discards events.
+type Logger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// New returns a Logger writing to w
+func New(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
+
+// Open creates the Logger described by the audit configuration section. It
+// returns nil when auditing is disabled.
+func Open(cfg config.AuditConfig) (*Logger, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	switch cfg.Sink {
+	case "", "stderr":
+		return New(os.Stderr), nil
+	case "file":
+		f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("opening audit log: %w", err)
+		}
+		return &Logger{w: f, closer: f}, nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", cfg.Sink)
+	}
+}
+
+// Record writes an event, stamping it with the current time if unset
+func (l *Logger) Record(e Event) {
+	if l == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "audit: failed to encode event: %v\n", err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(append(data, '\n')); err != nil {
+		fmt.Fprintf(os.Stderr, "audit: failed to write event: %v\n", err)
+	}
+}
+
+// Close releases the underlying sink when the Logger owns it
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+func TestRecordWritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+
+	l.Record(Event{Type: EventConfigLoad, Outcome: OutcomeSuccess, Source: "config.yaml"})
+	l.Record(Event{Type: EventSecretResolved, Secret: "database.password"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+		t.Fatalf("Failed to parse audit line: %v", err)
+	}
+	if e.Type != EventConfigLoad || e.Outcome != OutcomeSuccess || e.Time.IsZero() {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+}
+
+func TestNilLoggerDiscards(t *testing.T) {
+	var l *Logger
+	l.Record(Event{Type: EventConfigLoad})
+	if err := l.Close(); err != nil {
+		t.Errorf("Expected nil error closing nil logger, got %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	l, err := Open(config.AuditConfig{Enabled: false, Sink: "file"})
+	if err != nil || l != nil {
+		t.Fatalf("Expected disabled audit to return nil logger, got %v, %v", l, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err = Open(config.AuditConfig{Enabled: true, Sink: "file", Path: path})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	l.Record(Event{Type: EventReload, Outcome: OutcomeRejected, Reasons: []string{"server.port: too low"}})
+	l.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if !strings.Contains(string(data), `"outcome":"rejected"`) {
+		t.Errorf("Expected rejected reload in audit log, got %s", data)
+	}
+}
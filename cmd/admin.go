@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+// adminConfigHandler serves GET /config on the admin listener: the running
+// configuration as a flat map of keys to values, with secrets redacted
+func adminConfigHandler(live *liveConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		settings := map[string]any{}
+		for _, s := range live.Get().Settings() {
+			if s.Secret && fmt.Sprint(s.Value) != "" {
+				s.Value = config.Redacted
+			}
+			settings[s.Key] = s.Value
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(settings)
+	}
+}
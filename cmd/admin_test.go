@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+func TestAdminConfigHandler(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.App.Name = "demo"
+	cfg.Server.Port = 8080
+	cfg.Database.Password = "hunter2"
+	srv := httptest.NewServer(adminConfigHandler(newLiveConfig(cfg)))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("Expected a JSON 200 response, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var settings map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&settings); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{"app.name": "demo", "server.port": float64(8080), "database.password": config.Redacted} {
+		if settings[key] != want {
+			t.Errorf("%s = %v, want %v", key, settings[key], want)
+		}
+	}
+
+	resp, err = http.Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodGet {
+		t.Errorf("Expected 405 allowing GET, got %d allowing %q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+}
+
+func TestAdminListensOnLoopbackByDefault(t *testing.T) {
+	if got := rootCmd.PersistentFlags().Lookup("admin-listen").DefValue; got != "127.0.0.1:9091" {
+		t.Errorf("Expected the unauthenticated admin endpoint to default to loopback, got %q", got)
+	}
+}
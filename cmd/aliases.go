@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// flagAliases maps deprecated flag spellings to their canonical flag names
+var flagAliases = map[string]string{}
+
+// aliasFlag registers a deprecated spelling of a persistent flag, so a flag can be
+// renamed without breaking existing scripts. The alias shares the canonical
+// flag's value and therefore its viper key; it is hidden from help, and pflag
+// prints a deprecation warning whenever it is used.
+func aliasFlag(cmd *cobra.Command, alias, canonical string) {
+	target := cmd.PersistentFlags().Lookup(canonical)
+	if target == nil {
+		flagErrors = append(flagErrors, fmt.Errorf("alias --%s refers to unknown flag --%s", alias, canonical))
+		return
+	}
+	if cmd.PersistentFlags().Lookup(alias) != nil || cmd.Flags().Lookup(alias) != nil {
+		flagErrors = append(flagErrors, fmt.Errorf("alias --%s of --%s collides with an existing flag on %q", alias, canonical, cmd.Name()))
+		return
+	}
+	cmd.PersistentFlags().Var(target.Value, alias, target.Usage)
+	aliasFlag := cmd.PersistentFlags().Lookup(alias)
+	aliasFlag.NoOptDefVal = target.NoOptDefVal
+	aliasFlag.Deprecated = fmt.Sprintf("use --%s instead", canonical)
+	aliasFlag.Hidden = true
+	flagAliases[alias] = canonical
+}
+
+// applyFlagAliases marks a canonical flag as changed when it was set through an
+// alias. Viper and the flag-group checks only look at the canonical flag, so
+// this must run after parsing and before the configuration is read.
+func applyFlagAliases(cmd *cobra.Command) {
+	for alias, canonical := range flagAliases {
+		if a := cmd.PersistentFlags().Lookup(alias); a != nil && a.Changed {
+			cmd.PersistentFlags().Lookup(canonical).Changed = true
+		}
+	}
+}
+
+// aliasesOf returns the deprecated spellings of a flag, sorted
+func aliasesOf(flagName string) []string {
+	var aliases []string
+	for alias, canonical := range flagAliases {
+		if canonical == flagName {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
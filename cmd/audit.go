@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/example/cobra-viper-demo/audit"
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/go-playground/validator/v10"
+)
+
+// auditLog is the audit sink configured under the audit section; nil when disabled
+var auditLog *audit.Logger
+
+// openAuditLog opens the audit sink on first use. The settings are read straight
+// from viper so that even loads failing to unmarshal can be audited. A sink that
+// cannot be opened disables audit logging with a warning.
+func openAuditLog() error {
+	if auditLog != nil {
+		return nil
+	}
+	logger, err := audit.Open(config.AuditConfig{
+		Enabled: v.GetBool("audit.enabled"),
+		Sink:    v.GetString("audit.sink"),
+		Path:    v.GetString("audit.path"),
+	})
+	if err != nil {
+		return reportWarnings(os.Stderr, []string{fmt.Sprintf("audit logging disabled: %v", err)})
+	}
+	auditLog = logger
+	return nil
+}
+
+// auditConfigLoad records the outcome of loading the configuration
+func auditConfigLoad(cfg *config.Config, err error) {
+	event := audit.Event{
+		Type:    audit.EventConfigLoad,
+		Outcome: audit.OutcomeSuccess,
+		Source:  v.ConfigFileUsed(),
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reasons = errorReasons(err)
+	} else {
+		event.Hash = cfg.Hash()
+	}
+	auditLog.Record(event)
+}
+
+// errorReasons flattens a load or validation error into one reason per problem
+func errorReasons(err error) []string {
+	var rulesErr *config.RulesError
+	if errors.As(err, &rulesErr) {
+		reasons := make([]string, len(rulesErr.Violations))
+		for i, violation := range rulesErr.Violations {
+			reasons[i] = fmt.Sprintf("%s failed %q rule: %s", violation.Key, violation.Rule, violation.Message)
+		}
+		return reasons
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []string{err.Error()}
+	}
+	reasons := make([]string, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		reasons = append(reasons, fmt.Sprintf("%s failed %q validation", fe.Namespace(), fe.Tag()))
+	}
+	return reasons
+}
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/example/cobra-viper-demo/decrypt"
+	"github.com/spf13/pflag"
+)
+
+// configCacheEnv opts in to the compiled configuration cache
+const configCacheEnv = envPrefix + "_CONFIG_CACHE"
+
+// configCacheVersion is bumped whenever the cache layout changes; caches of
+// another version are ignored
+const configCacheVersion = 2
+
+// noConfigCache bypasses the cache for a single run, neither reading nor writing it
+var noConfigCache bool
+
+// configCache is the on-disk cache of the merged and validated configuration,
+// encoded with gob so extension values keep their Go types. Config is stored as
+// decoded with its secret fields cleared; they are read from viper again when
+// the cache is used, so no secret is written to disk.
+type configCache struct {
+	Version int
+	// Fingerprints identify the inputs the config was built from: "build"
+	// (the config schema and flag defaults), "file", "inputs" (flags and
+	// environment), and one entry per overlay source
+	Fingerprints map[string]string
+	// Overlays holds the settings fetched from each overlay source, except
+	// sources that set a secret field, which are fetched on every load
+	Overlays map[string]map[string]any
+	Config   *config.Config
+}
+
+func init() {
+	// The concrete types the config decoders put in Extensions and overlays
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+	gob.Register(time.Time{})
+}
+
+var (
+	// storedCache is the last cache read from or written to disk
+	storedCache *configCache
+
+	// pendingCache collects the fingerprints and overlay settings of the load
+	// in progress; it is written out once the load validates
+	pendingCache *configCache
+)
+
+// configCacheEnabled reports whether the cache is opted in and not bypassed
+func configCacheEnabled() bool {
+	if noConfigCache {
+		return false
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv(configCacheEnv))
+	return enabled
+}
+
+// configCachePath returns the cache file for the config file in use, under the
+// user cache directory
+func configCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	used := v.ConfigFileUsed()
+	if abs, err := filepath.Abs(used); err == nil && used != "" {
+		used = abs
+	}
+	sum := sha256.Sum256([]byte(used))
+	return filepath.Join(dir, "myapp", "config-"+hex.EncodeToString(sum[:8])+".gob"), nil
+}
+
+// beginConfigCache starts a load: it fingerprints the build, the config file,
+// and the flag and environment inputs, and reads the cache from disk if not
+// already known. An encrypted config file is never cached, since the cache
+// would hold its plaintext.
+func beginConfigCache() {
+	pendingCache = nil
+	if !configCacheEnabled() {
+		return
+	}
+	if fileEncrypted(v.ConfigFileUsed()) {
+		debugf("not caching the config: %s is encrypted", v.ConfigFileUsed())
+		return
+	}
+	pendingCache = &configCache{
+		Version: configCacheVersion,
+		Fingerprints: map[string]string{
+			"build":  buildFingerprint(),
+			"file":   fileFingerprint(v.ConfigFileUsed()),
+			"inputs": inputsFingerprint(),
+		},
+		Overlays: map[string]map[string]any{},
+	}
+	if storedCache != nil {
+		return
+	}
+
+	path, err := configCachePath()
+	if err != nil {
+		debugf("config cache unavailable: %v", err)
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			debugf("reading config cache: %v", err)
+		}
+		return
+	}
+	var cache configCache
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cache); err != nil || cache.Version != configCacheVersion {
+		debugf("ignoring unreadable config cache %s", path)
+		return
+	}
+	storedCache = &cache
+}
+
+// cachedOverlay returns the cached settings of an overlay source whose
+// fingerprint is unchanged, so the source does not need to be fetched
+func cachedOverlay(name, fingerprint string) (map[string]any, bool) {
+	if pendingCache == nil || storedCache == nil || fingerprint == "" {
+		return nil, false
+	}
+	if storedCache.Fingerprints[name] != fingerprint {
+		return nil, false
+	}
+	settings, ok := storedCache.Overlays[name]
+	return settings, ok
+}
+
+// recordOverlay adds the settings loaded from an overlay source to the pending
+// cache. Without a cheap fingerprint, the source is fingerprinted by content,
+// leaving out secret values. Settings that include a secret field are not
+// stored; cached secrets are always re-read from viper.
+func recordOverlay(name, fingerprint string, settings map[string]any) {
+	if pendingCache == nil {
+		return
+	}
+	public, hasSecrets := withoutSecrets("", settings, secretKeys())
+	if fingerprint == "" {
+		fingerprint = contentFingerprint(public)
+	}
+	pendingCache.Fingerprints[name] = fingerprint
+	if !hasSecrets {
+		pendingCache.Overlays[name] = settings
+	}
+}
+
+// recordSecretOverlay adds a source of credentials to the pending cache by its
+// version alone. Without a version, changes to the source could not be told
+// apart, so the load is not cached.
+func recordSecretOverlay(name, version string) {
+	if pendingCache == nil {
+		return
+	}
+	if version == "" {
+		skipConfigCache(name + " has no version")
+		return
+	}
+	pendingCache.Fingerprints[name] = "version:" + version
+}
+
+// skipConfigCache drops the pending cache, so the load in progress is not
+// written out
+func skipConfigCache(reason string) {
+	if pendingCache == nil {
+		return
+	}
+	debugf("not caching the config: %s", reason)
+	pendingCache = nil
+}
+
+// withoutSecrets returns a copy of nested settings without the secret keys,
+// and whether any secret key was present
+func withoutSecrets(prefix string, settings map[string]any, secret map[string]bool) (map[string]any, bool) {
+	public := make(map[string]any, len(settings))
+	found := false
+	for name, value := range settings {
+		key := strings.ToLower(name)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if secret[key] {
+			found = true
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok {
+			var nestedFound bool
+			value, nestedFound = withoutSecrets(key, nested, secret)
+			found = found || nestedFound
+		}
+		public[name] = value
+	}
+	return public, found
+}
+
+// cachedConfig returns the cached config when every input fingerprint matches
+// the current load, with its secret fields read from viper
+func cachedConfig() *config.Config {
+	if pendingCache == nil || storedCache == nil || storedCache.Config == nil {
+		return nil
+	}
+	if !maps.Equal(pendingCache.Fingerprints, storedCache.Fingerprints) {
+		return nil
+	}
+	cfg := storedCache.Config.Clone()
+	secret := secretKeys()
+	cfg.RewriteStrings(func(key, value string) (string, error) {
+		if secret[key] {
+			return v.GetString(key), nil
+		}
+		return value, nil
+	})
+	return cfg
+}
+
+// saveConfigCache writes the pending cache with the decoded config once it has
+// validated. Failures only disable caching, so they are reported as debug output.
+func saveConfigCache(cfg *config.Config) {
+	if pendingCache == nil {
+		return
+	}
+	pendingCache.Config = clearSecrets(cfg)
+	if storedCache != nil && maps.Equal(pendingCache.Fingerprints, storedCache.Fingerprints) {
+		return
+	}
+
+	path, err := configCachePath()
+	if err == nil {
+		err = writeConfigCache(path, pendingCache)
+	}
+	if err != nil {
+		debugf("writing config cache: %v", err)
+		return
+	}
+	storedCache = pendingCache
+	debugf("wrote config cache %s", path)
+}
+
+// clearSecrets returns a copy of cfg with its secret fields empty
+func clearSecrets(cfg *config.Config) *config.Config {
+	cleared := cfg.Clone()
+	secret := secretKeys()
+	cleared.RewriteStrings(func(key, value string) (string, error) {
+		if secret[key] {
+			return "", nil
+		}
+		return value, nil
+	})
+	return cleared
+}
+
+// writeConfigCache replaces the cache file atomically. The cache holds the
+// configuration, so it is only readable by the current user.
+func writeConfigCache(path string, cache *configCache) error {
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(cache); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.gob")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// fileFingerprint hashes the content of the config file
+func fileFingerprint(path string) string {
+	if path == "" {
+		return "none"
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "unreadable"
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// fileEncrypted reports whether the config file is age- or SOPS-encrypted
+func fileEncrypted(path string) bool {
+	if path == "" {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return decrypt.Detect(data, strings.TrimPrefix(filepath.Ext(path), ".")) != decrypt.None
+}
+
+// buildFingerprint hashes what the binary decodes the config with: the key,
+// type, and tags of every field, the flag defaults, and the module version, so
+// an upgrade never reuses a config decoded by another build
+func buildFingerprint() string {
+	var inputs []string
+	for _, f := range config.Fields() {
+		inputs = append(inputs, fmt.Sprintf("%s %s %q %t %q", f.Key, f.Type, f.Validate, f.Secret, f.Deprecated))
+	}
+	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		inputs = append(inputs, "--"+f.Name+"="+f.DefValue)
+	})
+	if info, ok := debug.ReadBuildInfo(); ok {
+		inputs = append(inputs, info.Main.Version)
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+				inputs = append(inputs, setting.Key+"="+setting.Value)
+			}
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(inputs, "\x00")))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// inputsFingerprint hashes the config-affecting flags set on the command line
+// and the environment variables carrying the application prefix. Variables
+// holding secrets count by name only, so no hash of a secret reaches the disk;
+// the cached config reads secret fields from viper again anyway.
+func inputsFingerprint() string {
+	names := []string{"config", "extensions-dir"}
+	for _, name := range flagBindings {
+		names = append(names, name)
+	}
+	var inputs []string
+	for _, name := range names {
+		// Changed is set on the shared flag whichever command parsed it
+		if f := rootCmd.PersistentFlags().Lookup(name); f != nil && f.Changed {
+			inputs = append(inputs, "--"+f.Name+"="+f.Value.String())
+		}
+	}
+	secretEnv := map[string]bool{decrypt.KeyEnv: true}
+	for key := range secretKeys() {
+		secretEnv[envVarName(key)] = true
+	}
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		switch {
+		case !strings.HasPrefix(name, envPrefix+"_"):
+		case secretEnv[name]:
+			inputs = append(inputs, name)
+		default:
+			inputs = append(inputs, kv)
+		}
+	}
+	sort.Strings(inputs)
+	sum := sha256.Sum256([]byte(strings.Join(inputs, "\x00")))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// contentFingerprint hashes fetched settings; encoding/json sorts map keys, so
+// equal settings always hash the same
+func contentFingerprint(settings map[string]any) string {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Sprintf("unhashable:%v", err)
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
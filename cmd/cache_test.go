@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/example/cobra-viper-demo/decrypt"
+)
+
+func TestExtensionsDirFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	if fp, err := extensionsDirFingerprint(filepath.Join(dir, "missing")); err != nil || fp != "absent" {
+		t.Fatalf("Expected absent fingerprint, got %q, %v", fp, err)
+	}
+
+	path := filepath.Join(dir, "cache.yaml")
+	if err := os.WriteFile(path, []byte("ttl: 60\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := extensionsDirFingerprint(dir)
+	if again, _ := extensionsDirFingerprint(dir); again != before {
+		t.Errorf("Fingerprint not stable: %q vs %q", before, again)
+	}
+
+	// Non-fragment files do not affect the fingerprint
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("notes"), 0o644)
+	if got, _ := extensionsDirFingerprint(dir); got != before {
+		t.Errorf("Expected non-YAML file to be ignored, got %q", got)
+	}
+
+	os.WriteFile(path, []byte("ttl: 120\n"), 0o644)
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	if after, _ := extensionsDirFingerprint(dir); after == before {
+		t.Error("Expected fingerprint to change when a fragment changes")
+	}
+}
+
+func TestCachedOverlay(t *testing.T) {
+	defer func() { storedCache, pendingCache = nil, nil }()
+
+	settings := map[string]any{"extensions": map[string]any{"cache": map[string]any{"ttl": 60}}}
+	storedCache = &configCache{
+		Version:      configCacheVersion,
+		Fingerprints: map[string]string{"extensions.d": "fp1"},
+		Overlays:     map[string]map[string]any{"extensions.d": settings},
+	}
+	pendingCache = &configCache{Fingerprints: map[string]string{}, Overlays: map[string]map[string]any{}}
+
+	if _, ok := cachedOverlay("extensions.d", "fp1"); !ok {
+		t.Error("Expected cache hit for unchanged fingerprint")
+	}
+	if _, ok := cachedOverlay("extensions.d", "fp2"); ok {
+		t.Error("Expected cache miss for changed fingerprint")
+	}
+	if _, ok := cachedOverlay("extensions.d", ""); ok {
+		t.Error("Expected cache miss for source without fingerprint")
+	}
+
+	// Sources without a cheap fingerprint are fingerprinted by content
+	recordOverlay("registry", "", settings)
+	if pendingCache.Fingerprints["registry"] != contentFingerprint(settings) {
+		t.Errorf("Expected content fingerprint, got %q", pendingCache.Fingerprints["registry"])
+	}
+}
+
+func TestConfigCacheKeepsSecretsOffDisk(t *testing.T) {
+	defer func() { storedCache, pendingCache = nil, nil }()
+	t.Setenv(configCacheEnv, "true")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	useViper(t, map[string]any{"database.password": "hunter2"})
+
+	cfg := &config.Config{}
+	cfg.Database.Host = "db"
+	cfg.Database.Password = "hunter2"
+	cfg.Extensions = map[string]any{"cache": map[string]any{"ttl": 60, "nodes": []any{"a", nil}}}
+	registry := map[string]any{"database": map[string]any{"host": "db", "password": "hunter2"}}
+
+	beginConfigCache()
+	recordOverlay("registry", "", registry)
+	if _, stored := pendingCache.Overlays["registry"]; stored {
+		t.Error("Expected settings with a secret not to be cached")
+	}
+	saveConfigCache(cfg)
+	if cfg.Database.Password != "hunter2" {
+		t.Error("Expected saving the cache to leave the config untouched")
+	}
+
+	path, err := configCachePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected a cache file: %v", err)
+	}
+	if bytes.Contains(data, []byte("hunter2")) {
+		t.Error("Expected no secret in the cache file")
+	}
+
+	// A later run reads the cache from disk and the secret from viper
+	storedCache = nil
+	v.Set("database.password", "rotated")
+	beginConfigCache()
+	recordOverlay("registry", "", registry)
+	cached := cachedConfig()
+	if cached == nil {
+		t.Fatal("Expected a cache hit")
+	}
+	if cached.Database.Password != "rotated" {
+		t.Errorf("Expected the password to be re-read from viper, got %q", cached.Database.Password)
+	}
+	if !reflect.DeepEqual(cached.Extensions, cfg.Extensions) {
+		t.Errorf("Expected extensions to keep their types, got %#v", cached.Extensions)
+	}
+}
+
+func TestRecordSecretOverlay(t *testing.T) {
+	defer func() { pendingCache = nil }()
+
+	pendingCache = &configCache{Fingerprints: map[string]string{}, Overlays: map[string]map[string]any{}}
+	recordSecretOverlay("Secret/myapp", "42")
+	if got := pendingCache.Fingerprints["Secret/myapp"]; got != "version:42" {
+		t.Errorf("Expected the resource version as fingerprint, got %q", got)
+	}
+	if _, stored := pendingCache.Overlays["Secret/myapp"]; stored {
+		t.Error("Expected no settings of a secret source to be cached")
+	}
+
+	recordSecretOverlay("Secret/myapp", "")
+	if pendingCache != nil {
+		t.Error("Expected a secret source without a version to disable caching the load")
+	}
+}
+
+func TestConfigCacheSkipsEncryptedInputs(t *testing.T) {
+	defer func() { storedCache, pendingCache = nil, nil }()
+	t.Setenv(configCacheEnv, "true")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	useViper(t, nil)
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(decrypt.KeyEnv, identity.String())
+	var encrypted bytes.Buffer
+	w, err := age.Encrypt(&encrypted, identity.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("ttl: 60\n"))
+	w.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, encrypted.Bytes(), 0o600)
+	v.SetConfigFile(path)
+	beginConfigCache()
+	if pendingCache != nil {
+		t.Error("Expected an encrypted config file not to be cached")
+	}
+
+	os.WriteFile(path, []byte("app:\n  name: demo\n"), 0o644)
+	savedDir := extensionsDir
+	defer func() { extensionsDir = savedDir }()
+	extensionsDir = filepath.Join(dir, "extensions.d")
+	os.Mkdir(extensionsDir, 0o755)
+	os.WriteFile(filepath.Join(extensionsDir, "cache.yaml"), encrypted.Bytes(), 0o600)
+	mergeOverlays()
+	if pendingCache != nil {
+		t.Error("Expected a load with an encrypted fragment not to be cached")
+	}
+}
+
+func TestFingerprintsLeaveOutSecrets(t *testing.T) {
+	if buildFingerprint() != buildFingerprint() {
+		t.Error("Expected a stable build fingerprint")
+	}
+
+	t.Setenv(envVarName("database.password"), "hunter2")
+	t.Setenv(envVarName("database.host"), "db1")
+	before := inputsFingerprint()
+	t.Setenv(envVarName("database.password"), "rotated")
+	if inputsFingerprint() != before {
+		t.Error("Expected a secret environment variable to count by name only")
+	}
+	t.Setenv(envVarName("database.host"), "db2")
+	if inputsFingerprint() == before {
+		t.Error("Expected a changed environment variable to change the fingerprint")
+	}
+}
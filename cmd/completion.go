@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion",
+	Short: "Generate shell completion scripts",
+	Long: `Generates a tab-completion script for bash, zsh, fish, or PowerShell.
+Run "completion <shell> --help" for installation instructions for your shell.`,
+	Args: cobra.NoArgs,
+}
+
+// completionShells lists the supported shells with their generator and
+// installation instructions; %[1]s is the program name
+var completionShells = []struct {
+	name     string
+	generate func(cmd *cobra.Command) error
+	install  string
+}{
+	{
+		name: "bash",
+		generate: func(cmd *cobra.Command) error {
+			return rootCmd.GenBashCompletionV2(cmd.OutOrStdout(), true)
+		},
+		install: `Requires the bash-completion package.
+
+Load completions in the current shell:
+
+  source <(%[1]s completion bash)
+
+Load completions for every new session:
+
+  # Linux
+  %[1]s completion bash > /etc/bash_completion.d/%[1]s
+  # macOS (Homebrew)
+  %[1]s completion bash > $(brew --prefix)/etc/bash_completion.d/%[1]s`,
+	},
+	{
+		name: "zsh",
+		generate: func(cmd *cobra.Command) error {
+			return rootCmd.GenZshCompletion(cmd.OutOrStdout())
+		},
+		install: `If completion is not already enabled, add this to ~/.zshrc once:
+
+  autoload -U compinit; compinit
+
+Load completions for every new session:
+
+  %[1]s completion zsh > "${fpath[1]}/_%[1]s"
+
+Start a new shell for the change to take effect.`,
+	},
+	{
+		name: "fish",
+		generate: func(cmd *cobra.Command) error {
+			return rootCmd.GenFishCompletion(cmd.OutOrStdout(), true)
+		},
+		install: `Load completions in the current shell:
+
+  %[1]s completion fish | source
+
+Load completions for every new session:
+
+  %[1]s completion fish > ~/.config/fish/completions/%[1]s.fish`,
+	},
+	{
+		name: "powershell",
+		generate: func(cmd *cobra.Command) error {
+			return rootCmd.GenPowerShellCompletionWithDesc(cmd.OutOrStdout())
+		},
+		install: `Load completions in the current shell:
+
+  %[1]s completion powershell | Out-String | Invoke-Expression
+
+Load completions for every new session by adding the line above to your
+PowerShell profile ($PROFILE).`,
+	},
+}
+
+// isCompletionRequest reports whether args invoke completion, which must not load
+// the configuration: any message it prints would end up in the user's terminal
+func isCompletionRequest(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd, completionCmd.Name():
+		return true
+	}
+	return false
+}
+
+// registerValueCompletions completes config flags whose key only accepts a fixed
+// set of values (a oneof validation), such as --app-environment
+func registerValueCompletions() {
+	for _, field := range config.Fields() {
+		flagName, ok := flagBindings[field.Key]
+		if !ok {
+			continue
+		}
+		for _, tag := range strings.Split(field.Validate, ",") {
+			if values, ok := strings.CutPrefix(tag, "oneof="); ok {
+				rootCmd.RegisterFlagCompletionFunc(flagName, cobra.FixedCompletions(strings.Fields(values), cobra.ShellCompDirectiveNoFileComp))
+			}
+		}
+	}
+}
+
+func init() {
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	for _, shell := range completionShells {
+		completionCmd.AddCommand(&cobra.Command{
+			Use:                   shell.name,
+			Short:                 fmt.Sprintf("Generate the completion script for %s", shell.name),
+			Long:                  fmt.Sprintf("Generates the completion script for %s.\n\n", shell.name) + fmt.Sprintf(shell.install, rootCmd.Name()),
+			Args:                  cobra.NoArgs,
+			DisableFlagsInUseLine: true,
+			Run: func(cmd *cobra.Command, args []string) {
+				if err := shell.generate(cmd); err != nil {
+					fmt.Fprintf(os.Stderr, "Error generating %s completion: %v\n", shell.name, err)
+					os.Exit(1)
+				}
+			},
+		})
+	}
+	rootCmd.AddCommand(completionCmd)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestIsCompletionRequest(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{cobra.ShellCompRequestCmd, "config", ""}, true},
+		{[]string{cobra.ShellCompNoDescRequestCmd, "--app-environment", ""}, true},
+		{[]string{"completion", "bash"}, true},
+		{[]string{"validate"}, false},
+		{[]string{"--config", "completion"}, false},
+	}
+	for _, tt := range tests {
+		if got := isCompletionRequest(tt.args); got != tt.want {
+			t.Errorf("isCompletionRequest(%q) = %t, want %t", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestInitConfigSkipsCompletionRequests(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("app:\n  name: from-file\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	t.Setenv("MYAPP_CONFIG", "")
+	useViper(t, nil)
+	savedArgs, savedFile := os.Args, cfgFile
+	defer func() { os.Args, cfgFile = savedArgs, savedFile }()
+	cfgFile = ""
+
+	for _, args := range [][]string{
+		{cobra.ShellCompRequestCmd, "config", ""},
+		{"completion", "zsh"},
+	} {
+		os.Args = append([]string{"myapp"}, args...)
+		initConfig()
+		if used := v.ConfigFileUsed(); used != "" || v.IsSet("app.name") {
+			t.Errorf("Expected %q not to load the config, got file %q", args, used)
+		}
+	}
+
+	os.Args = []string{"myapp", "validate"}
+	initConfig()
+	if got := v.GetString("app.name"); got != "from-file" {
+		t.Errorf("Expected other commands to load the config, got app.name %q", got)
+	}
+}
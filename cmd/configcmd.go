@@ -0,0 +1,12 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and manage the application configuration",
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/example/cobra-viper-demo/decrypt"
+	"github.com/spf13/cobra"
+)
+
+var encryptRecipients []string
+
+var encryptValueCmd = &cobra.Command{
+	Use:   "encrypt-value [value]",
+	Short: "Encrypt a single value for use as an inline enc: setting",
+	Long: `Encrypts a value to one or more age recipients and prints it with the enc: prefix,
+ready to paste into the config file. The value is read from stdin when not given
+as an argument, which keeps it out of the shell history.
+
+  echo -n 's3cret' | myapp config encrypt-value -r age1...`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(encryptRecipients) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: at least one --recipient is required")
+			os.Exit(1)
+		}
+		var recipients []age.Recipient
+		for _, r := range encryptRecipients {
+			recipient, err := age.ParseX25519Recipient(r)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid recipient %q: %v\n", r, err)
+				os.Exit(1)
+			}
+			recipients = append(recipients, recipient)
+		}
+
+		var plaintext string
+		if len(args) == 1 {
+			plaintext = args[0]
+		} else {
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading value: %v\n", err)
+				os.Exit(1)
+			}
+			plaintext = strings.TrimRight(string(data), "\r\n")
+		}
+
+		value, err := decrypt.EncryptValue(plaintext, recipients...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encrypting value: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), value)
+	},
+}
+
+func init() {
+	encryptValueCmd.Flags().StringSliceVarP(&encryptRecipients, "recipient", "r", nil, "age recipient (age1...) to encrypt to; repeatable")
+	configCmd.AddCommand(encryptValueCmd)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/spf13/cobra"
+)
+
+var envVarsCmd = &cobra.Command{
+	Use:   "env-vars",
+	Short: "List every supported environment variable",
+	Long: `Lists all MYAPP_* environment variables derived from the configuration struct,
+together with the config key they map to, its type, default value, constraints,
+and whether the variable is set in the current environment.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VARIABLE\tKEY\tTYPE\tDEFAULT\tCONSTRAINTS\tSET")
+		for _, field := range config.Fields() {
+			name := envVarName(field.Key)
+			set := "no"
+			if _, ok := os.LookupEnv(name); ok {
+				set = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				name, field.Key, field.TypeName(), orDash(defaultValue(field.Key)), orDash(field.Constraints()), set)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envVarsCmd)
+}
+
+// orDash renders empty table cells as "-"
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+func TestEnvVarName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"app.name", "MYAPP_APP_NAME"},
+		{"server.port", "MYAPP_SERVER_PORT"},
+		{"server.shutdown.grace_period", "MYAPP_SERVER_SHUTDOWN_GRACE_PERIOD"},
+		{"database.password_file", "MYAPP_DATABASE_PASSWORD_FILE"},
+		{"server.tls.cert", "MYAPP_SERVER_TLS_CERT"},
+	}
+	for _, tt := range tests {
+		if got := envVarName(tt.key); got != tt.want {
+			t.Errorf("envVarName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+// TestFieldEnvVarsAreUnique checks that no two configuration keys share an
+// environment variable, which would make one of them impossible to set alone
+func TestFieldEnvVarsAreUnique(t *testing.T) {
+	seen := map[string]string{}
+	for _, field := range config.Fields() {
+		name := envVarName(field.Key)
+		if other, dup := seen[name]; dup {
+			t.Errorf("%s and %s both map to %s", other, field.Key, name)
+		}
+		seen[name] = field.Key
+	}
+	for _, key := range []string{"app.name", "server.shutdown.drain_timeout", "database.password"} {
+		if _, ok := seen[envVarName(key)]; !ok {
+			t.Errorf("Expected %s in the field catalog", key)
+		}
+	}
+}
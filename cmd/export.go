@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// How export subcommands treat secret-tagged fields
+const (
+	secretsOmit    = "omit"
+	secretsRedact  = "redact"
+	secretsInclude = "include"
+)
+
+// secretsOptions holds the --secrets and --confirm-secrets flags of commands
+// that write the configuration out
+type secretsOptions struct {
+	secrets        string
+	confirmSecrets bool
+}
+
+var exportOpts secretsOptions
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the effective configuration in other formats",
+	Long: `Export the effective configuration in other formats.
+
+Secret fields are redacted by default. Use --secrets omit to drop them (e.g. for
+documentation), or --secrets include --confirm-secrets to emit real values (e.g.
+to bootstrap a deployment).`,
+}
+
+var dotenvOpts struct {
+	output      string
+	onlyChanged bool
+	secretsFile string
+}
+
+var exportDotenvCmd = &cobra.Command{
+	Use:   "dotenv",
+	Short: "Export the effective configuration as a KEY=value env file",
+	Long: `Writes the effective configuration as MYAPP_* KEY=value lines, suitable for
+Docker Compose "env_file:" usage. With --secrets include, secret values can be
+redirected to a separate file so the main file can be shared or committed safely.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if dotenvOpts.secretsFile != "" && exportOpts.secrets != secretsInclude {
+			fmt.Fprintln(os.Stderr, "Error: --secrets-file requires --secrets include")
+			os.Exit(1)
+		}
+		exportOpts.mustCheck()
+		cfg := mustLoadConfig()
+
+		main, secrets := dotenvFiles(mustExportSettings(cfg, dotenvOpts.onlyChanged), dotenvOpts.secretsFile != "")
+		if dotenvOpts.secretsFile != "" {
+			if err := writeOutput(dotenvOpts.secretsFile, secrets, 0o600); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing secrets file: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		// Secret values left in the main file keep it owner-only
+		perm := os.FileMode(0o644)
+		if exportOpts.secrets == secretsInclude && dotenvOpts.secretsFile == "" {
+			perm = 0o600
+		}
+		if err := writeOutput(dotenvOpts.output, main, perm); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing env file: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// dotenvFiles renders settings as KEY=value lines, moving secret keys to the
+// second file when splitSecrets is set
+func dotenvFiles(settings []config.Setting, splitSecrets bool) (main, secrets []byte) {
+	var mainBuf, secretsBuf bytes.Buffer
+	for _, s := range settings {
+		line := fmt.Sprintf("%s=%s\n", envVarName(s.Key), dotenvQuote(fmt.Sprint(s.Value)))
+		if s.Secret && splitSecrets {
+			secretsBuf.WriteString(line)
+		} else {
+			mainBuf.WriteString(line)
+		}
+	}
+	return mainBuf.Bytes(), secretsBuf.Bytes()
+}
+
+func init() {
+	exportOpts.addFlags(exportCmd, exportCmd.PersistentFlags())
+
+	exportDotenvCmd.Flags().StringVarP(&dotenvOpts.output, "output", "o", "", "write to file instead of stdout")
+	exportDotenvCmd.Flags().BoolVar(&dotenvOpts.onlyChanged, "only-changed", false, "only include keys that differ from their defaults")
+	exportDotenvCmd.Flags().StringVar(&dotenvOpts.secretsFile, "secrets-file", "", "write secret keys to this file instead of the main output (requires --secrets include)")
+
+	exportCmd.AddCommand(exportDotenvCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+// addFlags registers --secrets and --confirm-secrets on flags of cmd
+func (o *secretsOptions) addFlags(cmd *cobra.Command, flags *pflag.FlagSet) {
+	flags.StringVar(&o.secrets, "secrets", secretsRedact, "how to output secret fields: omit, redact, or include")
+	cmd.RegisterFlagCompletionFunc("secrets", cobra.FixedCompletions([]string{secretsOmit, secretsRedact, secretsInclude}, cobra.ShellCompDirectiveNoFileComp))
+	flags.BoolVar(&o.confirmSecrets, "confirm-secrets", false, "confirm that --secrets include may write secret values in clear text")
+}
+
+// check rejects an unknown --secrets value, and include without confirmation
+func (o secretsOptions) check() error {
+	switch o.secrets {
+	case secretsOmit, secretsRedact:
+	case secretsInclude:
+		if !o.confirmSecrets {
+			return errors.New("--secrets include writes secret values in clear text; add --confirm-secrets to proceed")
+		}
+	default:
+		return fmt.Errorf("invalid --secrets value %q (expected omit, redact, or include)", o.secrets)
+	}
+	return nil
+}
+
+// mustCheck is check, exiting on an invalid --secrets setting
+func (o secretsOptions) mustCheck() {
+	if err := o.check(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// scrub returns a copy of cfg with secret fields cleared (omit), masked
+// (redact), or kept (include). Callers check the options first.
+func (o secretsOptions) scrub(cfg *config.Config) *config.Config {
+	secret := secretKeys()
+	scrubbed := cfg.Clone()
+	scrubbed.RewriteStrings(func(key, value string) (string, error) {
+		if !secret[key] || value == "" {
+			return value, nil
+		}
+		switch o.secrets {
+		case secretsOmit:
+			return "", nil
+		case secretsRedact:
+			return config.Redacted, nil
+		}
+		return value, nil
+	})
+	return scrubbed
+}
+
+// exportSettings returns the settings of cfg to export, optionally skipping
+// keys that still hold their default value. Secret fields are dropped, masked,
+// or kept according to --secrets.
+func exportSettings(cfg *config.Config, onlyChanged bool) ([]config.Setting, error) {
+	if err := exportOpts.check(); err != nil {
+		return nil, err
+	}
+
+	var settings []config.Setting
+	for _, s := range cfg.Settings() {
+		if onlyChanged && fmt.Sprint(s.Value) == defaultValue(s.Key) {
+			continue
+		}
+		if s.Secret {
+			switch exportOpts.secrets {
+			case secretsOmit:
+				continue
+			case secretsRedact:
+				if fmt.Sprint(s.Value) != "" {
+					s.Value = config.Redacted
+				}
+			}
+		}
+		settings = append(settings, s)
+	}
+	return settings, nil
+}
+
+// mustExportSettings is exportSettings, exiting on an invalid --secrets setting
+func mustExportSettings(cfg *config.Config, onlyChanged bool) []config.Setting {
+	settings, err := exportSettings(cfg, onlyChanged)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return settings
+}
+
+// dotenvQuote double-quotes a value when it contains characters that env file
+// parsers would otherwise interpret
+func dotenvQuote(value string) string {
+	if !strings.ContainsAny(value, " \t\n\"'#$\\=`") {
+		return value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "\n", `\n`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// writeOutput writes data to path, or to stdout when path is empty or "-"
+func writeOutput(path string, data []byte, perm os.FileMode) error {
+	if path == "" || path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSystemdQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"", ""},
+		{`say "hi"`, `"say \"hi\""`},
+		{"it's", `"it's"`},
+		{"$HOME/data", `"\$HOME/data"`},
+		{"line1\nline2", "\"line1\nline2\""},
+		{"100%", "100%"},
+		{"%h/data", "%h/data"},
+		{"trailing ", `"trailing "`},
+		{`C:\dir`, `"C:\\dir"`},
+		{"a;b#c", `"a;b#c"`},
+	}
+	for _, tt := range tests {
+		if got := systemdQuote(tt.value); got != tt.want {
+			t.Errorf("systemdQuote(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSystemdDropInEscapesSpecifiers(t *testing.T) {
+	saved := systemdOpts
+	defer func() { systemdOpts = saved }()
+	systemdOpts.unit = "myapp.service"
+	systemdOpts.envFilePath = "/etc/myapp/100%.env"
+
+	if got := systemdDropIn(); !strings.Contains(got, "EnvironmentFile=/etc/myapp/100%%.env\n") {
+		t.Errorf("Expected the %% in the path to be doubled, got:\n%s", got)
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+func TestDotenvQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"", ""},
+		{`say "hi"`, `"say \"hi\""`},
+		{"it's", `"it's"`},
+		{"$HOME/data", `"\$HOME/data"`},
+		{"line1\nline2", `"line1\nline2"`},
+		{"pass#word", `"pass#word"`},
+		{`C:\dir`, `"C:\\dir"`},
+		{"a=b", `"a=b"`},
+		{"`cmd`", "\"\\`cmd\\`\""},
+	}
+	for _, tt := range tests {
+		if got := dotenvQuote(tt.value); got != tt.want {
+			t.Errorf("dotenvQuote(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExportSettingsSecrets(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.App.Name = "demo"
+	cfg.Database.Host = "db"
+	cfg.Database.Password = "hunter2"
+
+	tests := []struct {
+		name    string
+		opts    secretsOptions
+		want    any // database.password; nil when the key is dropped
+		wantErr bool
+	}{
+		{name: "omit", opts: secretsOptions{secrets: secretsOmit}, want: nil},
+		{name: "redact", opts: secretsOptions{secrets: secretsRedact}, want: config.Redacted},
+		{name: "include without confirmation", opts: secretsOptions{secrets: secretsInclude}, wantErr: true},
+		{name: "include", opts: secretsOptions{secrets: secretsInclude, confirmSecrets: true}, want: "hunter2"},
+		{name: "invalid", opts: secretsOptions{secrets: "plain"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := exportOpts
+			defer func() { exportOpts = saved }()
+			exportOpts = tt.opts
+
+			settings, err := exportSettings(cfg, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("exportSettings failed: %v", err)
+			}
+			values := map[string]any{}
+			for _, s := range settings {
+				values[s.Key] = s.Value
+			}
+			if got, ok := values["database.password"]; !reflect.DeepEqual(got, tt.want) || ok != (tt.want != nil) {
+				t.Errorf("database.password = %v (present %t), want %v", got, ok, tt.want)
+			}
+			if values["database.host"] != "db" {
+				t.Errorf("Expected non-secret keys to be exported as is, got %v", values["database.host"])
+			}
+		})
+	}
+}
+
+func TestDotenvFilesSplitSecrets(t *testing.T) {
+	settings := []config.Setting{
+		{Field: config.Field{Key: "database.host"}, Value: "db"},
+		{Field: config.Field{Key: "database.password", Secret: true}, Value: "hunter2"},
+	}
+
+	main, secrets := dotenvFiles(settings, true)
+	if string(main) != "MYAPP_DATABASE_HOST=db\n" || string(secrets) != "MYAPP_DATABASE_PASSWORD=hunter2\n" {
+		t.Errorf("Expected the password in the secrets file only, got main %q and secrets %q", main, secrets)
+	}
+
+	main, secrets = dotenvFiles(settings, false)
+	if string(main) != "MYAPP_DATABASE_HOST=db\nMYAPP_DATABASE_PASSWORD=hunter2\n" || len(secrets) != 0 {
+		t.Errorf("Expected every key in the main file, got main %q and secrets %q", main, secrets)
+	}
+}
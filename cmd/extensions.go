@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/example/cobra-viper-demo/decrypt"
+	"go.yaml.in/yaml/v3"
+)
+
+// extensionsDirName is the directory, next to the config file, holding
+// configuration fragments contributed by plugins
+const extensionsDirName = "extensions.d"
+
+var (
+	// extensionsDir overrides the location of the extensions.d directory
+	extensionsDir string
+
+	// extensionsLoadErr holds the error from the last extensions.d load, which
+	// fails the next configuration load instead of silently dropping a fragment
+	extensionsLoadErr error
+
+	// extensionsKeys holds the viper keys provided by extensions.d fragments
+	extensionsKeys = map[string]bool{}
+)
+
+// extensionsDirPath returns --extensions-dir, or extensions.d next to the config
+// file in use, or extensions.d in the current directory
+func extensionsDirPath() string {
+	if extensionsDir != "" {
+		return extensionsDir
+	}
+	if used := v.ConfigFileUsed(); used != "" {
+		return filepath.Join(filepath.Dir(used), extensionsDirName)
+	}
+	return extensionsDirName
+}
+
+// extensionsOverlay loads every YAML file in the extensions directory into
+// extensions.<file name>, overriding the same extension in the config file
+func extensionsOverlay() overlaySource {
+	dir := extensionsDirPath()
+	var encrypted atomic.Bool
+	return overlaySource{
+		name: "extensions.d",
+		fetch: func(ctx context.Context) (map[string]any, error) {
+			exts, enc, err := readExtensionsDir(ctx, dir)
+			encrypted.Store(enc)
+			return exts, err
+		},
+		apply: func(exts map[string]any, err error) {
+			applyExtensions(dir, exts, err)
+		},
+		fingerprint: func() (string, error) {
+			return extensionsDirFingerprint(dir)
+		},
+		encrypted: encrypted.Load,
+	}
+}
+
+// extensionsDirFingerprint identifies the fragments of dir by name, size, and
+// modification time, without reading them
+func extensionsDirFingerprint(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "absent", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
+
+// applyExtensions merges extension fragments, recording any error so that it
+// fails the next configuration load instead of silently dropping a fragment
+func applyExtensions(dir string, exts map[string]any, err error) {
+	extensionsLoadErr = nil
+	extensionsKeys = map[string]bool{}
+	if err != nil {
+		extensionsLoadErr = err
+		return
+	}
+	if len(exts) == 0 {
+		return
+	}
+	if err := v.MergeConfigMap(map[string]any{"extensions": exts}); err != nil {
+		extensionsLoadErr = fmt.Errorf("merging %s: %w", dir, err)
+		return
+	}
+	collectKeys("extensions", exts, extensionsKeys)
+	fmt.Fprintf(os.Stderr, "Loaded %d extension(s) from %s\n", len(exts), dir)
+}
+
+// readExtensionsDir parses the *.yaml and *.yml files of dir, keyed by file name
+// without extension, and reports whether any of them was encrypted. A missing
+// directory yields no extensions.
+func readExtensionsDir(ctx context.Context, dir string) (map[string]any, bool, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading %s: %w", dir, err)
+	}
+
+	exts := map[string]any{}
+	encrypted := false
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(entry.Name(), ext))
+		if _, dup := exts[name]; dup {
+			return nil, false, fmt.Errorf("extension %q is defined by more than one file in %s", name, dir)
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, false, err
+		}
+		encrypted = encrypted || decrypt.Detect(data, "yaml") != decrypt.None
+		if data, err = decrypt.Decrypt(data, "yaml"); err != nil {
+			return nil, false, fmt.Errorf("reading %s: %w", path, err)
+		}
+		var content map[string]any
+		if err := yaml.Unmarshal(data, &content); err != nil {
+			return nil, false, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if content == nil {
+			content = map[string]any{}
+		}
+		exts[name] = content
+	}
+	return exts, encrypted, nil
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// canDefineFlag reports whether a persistent flag bound to viperKey can be
+// defined on cmd. Collisions are recorded in flagErrors instead of letting pflag
+// panic on the first one.
+func canDefineFlag(cmd *cobra.Command, viperKey, flagName, shorthand string) bool {
+	ok := true
+	for _, flags := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.Flags()} {
+		if flags.Lookup(flagName) != nil {
+			flagErrors = append(flagErrors, fmt.Errorf("flag --%s is defined more than once on %q", flagName, cmd.Name()))
+			ok = false
+			break
+		}
+	}
+	if shorthand != "" {
+		for _, flags := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.Flags()} {
+			if existing := flags.ShorthandLookup(shorthand); existing != nil {
+				flagErrors = append(flagErrors, fmt.Errorf("shorthand -%s of --%s collides with --%s on %q", shorthand, flagName, existing.Name, cmd.Name()))
+				ok = false
+				break
+			}
+		}
+	}
+	if existing, bound := flagBindings[viperKey]; bound {
+		flagErrors = append(flagErrors, fmt.Errorf("config key %s is bound to both --%s and --%s", viperKey, existing, flagName))
+		ok = false
+	}
+	return ok
+}
+
+// checkFlags returns every problem found while registering flags, plus the name
+// and shorthand collisions between persistent flags and the flags of subcommands
+// registered afterwards
+func checkFlags(root *cobra.Command) error {
+	return errors.Join(append(append([]error(nil), flagErrors...), flagCollisions(root)...)...)
+}
+
+// flagCollisions walks the command tree and reports flags whose name or shorthand
+// is already taken by a different flag visible on the same command
+func flagCollisions(root *cobra.Command) []error {
+	var errs []error
+	var walk func(cmd *cobra.Command, inherited []*pflag.Flag)
+	walk = func(cmd *cobra.Command, inherited []*pflag.Flag) {
+		names := map[string]*pflag.Flag{}
+		shorthands := map[string]*pflag.Flag{}
+		check := func(f *pflag.Flag) {
+			if existing, ok := names[f.Name]; ok {
+				if existing != f {
+					errs = append(errs, fmt.Errorf("flag --%s on %q shadows an inherited flag of the same name", f.Name, cmd.CommandPath()))
+				}
+				return
+			}
+			names[f.Name] = f
+			if f.Shorthand == "" {
+				return
+			}
+			if existing, ok := shorthands[f.Shorthand]; ok {
+				errs = append(errs, fmt.Errorf("shorthand -%s of --%s collides with --%s on %q", f.Shorthand, f.Name, existing.Name, cmd.CommandPath()))
+				return
+			}
+			shorthands[f.Shorthand] = f
+		}
+
+		for _, f := range inherited {
+			check(f)
+		}
+		var persistent []*pflag.Flag
+		cmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+			check(f)
+			persistent = append(persistent, f)
+		})
+		cmd.Flags().VisitAll(check)
+
+		childInherited := append(append([]*pflag.Flag(nil), inherited...), persistent...)
+		for _, child := range cmd.Commands() {
+			walk(child, childInherited)
+		}
+	}
+	walk(root, nil)
+	return errs
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestRegisteredFlagsHaveNoCollisions(t *testing.T) {
+	if err := checkFlags(rootCmd); err != nil {
+		t.Errorf("Expected no flag registration problems, got:\n%v", err)
+	}
+}
+
+func TestBindDetectsAllCollisions(t *testing.T) {
+	// Swap the package-level registration state for a scratch one
+	oldV, oldBindings, oldErrors := v, flagBindings, flagErrors
+	v, flagBindings, flagErrors = viper.New(), map[string]string{}, nil
+	defer func() { v, flagBindings, flagErrors = oldV, oldBindings, oldErrors }()
+
+	cmd := &cobra.Command{Use: "test"}
+	bindStringFlag(cmd, "app.name", "app-name", "n", "", "")
+	bindStringFlag(cmd, "app.version", "app-version", "v", "", "")
+	bindBoolFlag(cmd, "logging.verbose", "verbose", "v", false, "") // shorthand collision
+	bindStringFlag(cmd, "app.name", "name", "", "", "")             // duplicate key binding
+	bindIntFlag(cmd, "server.port", "app-name", "", 0, "")          // duplicate flag name
+	bindStringFlag(cmd, "server.host", "server-host", "", "", "")   // fine
+
+	if len(flagErrors) != 3 {
+		t.Fatalf("Expected 3 registration errors, got %d: %v", len(flagErrors), flagErrors)
+	}
+	msg := errors.Join(flagErrors...).Error()
+	for _, want := range []string{
+		"shorthand -v of --verbose collides with --app-version",
+		"config key app.name is bound to both --app-name and --name",
+		"flag --app-name is defined more than once",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected error containing %q, got:\n%s", want, msg)
+		}
+	}
+	if cmd.PersistentFlags().Lookup("server-host") == nil {
+		t.Error("Expected non-colliding flag to be defined")
+	}
+}
+
+func TestFlagCollisionsAcrossSubcommands(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().StringP("app-version", "v", "", "")
+	root.PersistentFlags().StringP("output", "o", "", "")
+
+	child := &cobra.Command{Use: "child"}
+	child.Flags().BoolP("verbose", "v", false, "")
+	child.Flags().StringP("out-file", "o", "", "")
+	root.AddCommand(child)
+
+	grandchild := &cobra.Command{Use: "grandchild"}
+	grandchild.Flags().String("app-version", "", "")
+	child.AddCommand(grandchild)
+
+	errs := flagCollisions(root)
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 collisions, got %d: %v", len(errs), errs)
+	}
+	msg := errors.Join(errs...).Error()
+	for _, want := range []string{
+		`shorthand -v of --verbose collides with --app-version on "root child"`,
+		`shorthand -o of --out-file collides with --output on "root child"`,
+		`flag --app-version on "root child grandchild" shadows an inherited flag`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected error containing %q, got:\n%s", want, msg)
+		}
+	}
+}
+
+func TestFlagAliases(t *testing.T) {
+	oldV, oldBindings, oldErrors, oldAliases := v, flagBindings, flagErrors, flagAliases
+	v, flagBindings, flagErrors, flagAliases = viper.New(), map[string]string{}, nil, map[string]string{}
+	defer func() { v, flagBindings, flagErrors, flagAliases = oldV, oldBindings, oldErrors, oldAliases }()
+
+	cmd := &cobra.Command{Use: "test"}
+	bindStringFlag(cmd, "database.host", "db-host", "", "", "")
+	bindBoolFlag(cmd, "audit.enabled", "audit-enabled", "", false, "")
+	aliasFlag(cmd, "db_host", "db-host")
+	aliasFlag(cmd, "audit_enabled", "audit-enabled")
+	aliasFlag(cmd, "db_port", "db-port") // unknown canonical flag
+	aliasFlag(cmd, "db-host", "db-host") // collides with the canonical flag
+	if len(flagErrors) != 2 {
+		t.Fatalf("Expected 2 registration errors, got %d: %v", len(flagErrors), flagErrors)
+	}
+
+	if err := cmd.ParseFlags([]string{"--db_host", "legacy", "--audit_enabled"}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	applyFlagAliases(cmd)
+
+	if !cmd.PersistentFlags().Lookup("db-host").Changed {
+		t.Error("Expected canonical flag to be marked as changed")
+	}
+	if got := v.GetString("database.host"); got != "legacy" {
+		t.Errorf("Expected database.host from alias, got %q", got)
+	}
+	if !v.GetBool("audit.enabled") {
+		t.Error("Expected boolean alias to work without a value")
+	}
+	if got := aliasesOf("db-host"); len(got) != 1 || got[0] != "db_host" {
+		t.Errorf("Expected [db_host], got %v", got)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/example/cobra-viper-demo/decrypt"
+	"github.com/spf13/cobra"
+)
+
+var fmtOpts struct {
+	write         bool
+	check         bool
+	stripDefaults bool
+}
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [file]",
+	Short: "Rewrite a YAML config file in canonical form",
+	Long: `Re-emits a YAML config file in canonical form: sections and keys in the order of
+the configuration struct, extensions sorted by name, and two-space indentation.
+Comments are preserved. Formats the config file in use unless a file is given.
+
+By default the result is printed; -w rewrites the file, and --check only reports
+whether the file is formatted, exiting with status 1 when it is not.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := v.ConfigFileUsed()
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if path == "" {
+			fmt.Fprintln(os.Stderr, "Error: no config file found; pass the file to format")
+			os.Exit(1)
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); ext != ".yaml" && ext != ".yml" {
+			fmt.Fprintf(os.Stderr, "Error: %s: only YAML config files can be formatted\n", path)
+			os.Exit(1)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		if decrypt.Detect(data, "yaml") != decrypt.None {
+			fmt.Fprintf(os.Stderr, "Error: %s is encrypted; format the plaintext before encrypting it\n", path)
+			os.Exit(1)
+		}
+
+		var drop func(key, value string) bool
+		if fmtOpts.stripDefaults {
+			drop = isDefaultSetting
+		}
+		formatted, err := config.FormatYAML(data, drop)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting %s: %v\n", path, err)
+			os.Exit(1)
+		}
+
+		switch {
+		case fmtOpts.check:
+			if !bytes.Equal(formatted, data) {
+				fmt.Fprintln(cmd.OutOrStdout(), path)
+				os.Exit(1)
+			}
+		case fmtOpts.write:
+			if bytes.Equal(formatted, data) {
+				return
+			}
+			info, err := os.Stat(path)
+			if err == nil {
+				err = os.WriteFile(path, formatted, info.Mode().Perm())
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", path, err)
+				os.Exit(1)
+			}
+		default:
+			cmd.OutOrStdout().Write(formatted)
+		}
+	},
+}
+
+// isDefaultSetting reports whether a config file value equals the key's default
+func isDefaultSetting(key, value string) bool {
+	for _, field := range config.Fields() {
+		if field.Key == key {
+			return value == defaultValue(key)
+		}
+	}
+	return false
+}
+
+func init() {
+	fmtCmd.Flags().BoolVarP(&fmtOpts.write, "write", "w", false, "write the result back to the file")
+	fmtCmd.Flags().BoolVar(&fmtOpts.check, "check", false, "report whether the file is formatted, exiting 1 when it is not")
+	fmtCmd.Flags().BoolVar(&fmtOpts.stripDefaults, "strip-defaults", false, "drop settings whose value equals the default")
+	fmtCmd.MarkFlagsMutuallyExclusive("write", "check")
+	configCmd.AddCommand(fmtCmd)
+}
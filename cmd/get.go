@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	getOutput  string
+	getSecrets secretsOptions
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Print the effective configuration or the value of one key",
+	Long: `Prints the value of a dotted key such as server.port, a whole section such as
+server, or the effective configuration when no key is given. Single values are
+printed as is and sections as JSON, unless --output selects a format. Secret
+values are redacted unless --secrets include --confirm-secrets is given.
+
+With --output go-template=<template>, the template is executed against the value,
+so scripts can extract exactly the shape they need. Fields use their Go names:
+
+  myapp config get -o go-template='{{.Server.Host}}:{{.Server.Port}}'
+  myapp config get server -o go-template='{{.Host}}:{{.Port}}'`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeConfigKeys,
+	Run: func(cmd *cobra.Command, args []string) {
+		p := printPlain
+		if getOutput != "" {
+			p = mustNewPrinter(getOutput)
+		}
+		getSecrets.mustCheck()
+		cfg := getSecrets.scrub(mustLoadConfig())
+
+		var value any = cfg
+		if len(args) == 1 && getOutput == outputFlat {
+			lines := flatLines(cfg, args[0])
+			if len(lines) == 0 {
+				fmt.Fprintf(os.Stderr, "Error: unknown configuration key %q\n", args[0])
+				os.Exit(1)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), strings.Join(lines, "\n"))
+			return
+		}
+		if len(args) == 1 {
+			var ok bool
+			if value, ok = cfg.Lookup(args[0]); !ok {
+				fmt.Fprintf(os.Stderr, "Error: unknown configuration key %q\n", args[0])
+				os.Exit(1)
+			}
+		}
+		mustPrint(cmd.OutOrStdout(), p, value)
+	},
+}
+
+func init() {
+	getCmd.Flags().StringVarP(&getOutput, "output", "o", "", outputUsage+" (default is the value as is, or json for sections)")
+	getCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(outputFormats, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace))
+	getSecrets.addFlags(getCmd, getCmd.Flags())
+	configCmd.AddCommand(getCmd)
+}
+
+// completeConfigKeys completes the first argument with configuration keys and
+// the sections holding them
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var keys []string
+	for _, field := range config.Fields() {
+		section, _, _ := strings.Cut(field.Key, ".")
+		if !slices.Contains(keys, section) {
+			keys = append(keys, section)
+		}
+		keys = append(keys, field.Key)
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
+
+// printPlain writes single values as is, for shell scripts, and anything with
+// structure as JSON
+func printPlain(w io.Writer, value any) error {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Pointer:
+		return printJSON(w, value)
+	}
+	_, err := fmt.Fprintln(w, value)
+	return err
+}
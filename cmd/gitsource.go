@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitSourcePrefix marks a --config value that names a file in a git repository
+const gitSourcePrefix = "git+"
+
+// gitPollInterval is how often serve checks the config repository for new commits
+var gitPollInterval time.Duration
+
+// gitConfig is the config repository in use, or nil when the config file is local
+var gitConfig *gitSource
+
+// gitSource is a config file in a git repository, written as
+// git+<repository URL>//<path in repository>?ref=<branch, tag, or commit>
+type gitSource struct {
+	Repo string
+	Path string
+	Ref  string
+	// Dir is the local checkout, under the user cache directory
+	Dir string
+}
+
+// parseGitSource parses a git+ config location; ok is false for plain paths
+func parseGitSource(raw string) (src gitSource, ok bool, err error) {
+	if !strings.HasPrefix(raw, gitSourcePrefix) {
+		return gitSource{}, false, nil
+	}
+	rest := strings.TrimPrefix(raw, gitSourcePrefix)
+	rest, query, _ := strings.Cut(rest, "?")
+
+	// The first "//" after the scheme separates the repository from the path
+	schemeEnd := strings.Index(rest, "://")
+	if schemeEnd < 0 {
+		return gitSource{}, true, fmt.Errorf("invalid git config source %q: missing URL scheme", raw)
+	}
+	sep := strings.Index(rest[schemeEnd+3:], "//")
+	if sep < 0 {
+		return gitSource{}, true, fmt.Errorf("invalid git config source %q: missing //<path> of the config file", raw)
+	}
+	src.Repo = rest[:schemeEnd+3+sep]
+	src.Path = strings.Trim(rest[schemeEnd+3+sep+2:], "/")
+	if src.Path == "" {
+		return gitSource{}, true, fmt.Errorf("invalid git config source %q: empty config file path", raw)
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return gitSource{}, true, fmt.Errorf("invalid git config source %q: %w", raw, err)
+	}
+	src.Ref = params.Get("ref")
+	if src.Ref == "" {
+		src.Ref = "HEAD"
+	}
+	// git would take either as an option
+	if strings.HasPrefix(src.Repo, "-") || strings.HasPrefix(src.Ref, "-") {
+		return gitSource{}, true, fmt.Errorf("invalid git config source %q: repository and ref must not start with \"-\"", raw)
+	}
+	return src, true, nil
+}
+
+// File returns the config file inside the local checkout
+func (s gitSource) File() string {
+	return filepath.Join(s.Dir, filepath.FromSlash(s.Path))
+}
+
+// checkoutDir returns the local checkout of the repository and ref, under the user
+// cache directory
+func (s gitSource) checkoutDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(s.Repo + "\x00" + s.Ref))
+	return filepath.Join(dir, "myapp", "git-"+hex.EncodeToString(sum[:8])), nil
+}
+
+// resolveGitConfig checks out the config repository and returns the path of the
+// config file in the checkout. When fetching fails and an earlier checkout exists,
+// the earlier commit is used with a warning so a git outage does not stop startup,
+// unless warnings are strict.
+func resolveGitConfig(raw string) (string, error) {
+	src, _, err := parseGitSource(raw)
+	if err != nil {
+		return "", err
+	}
+	if src.Dir, err = src.checkoutDir(); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), overlayTimeout)
+	defer cancel()
+	start := time.Now()
+	commit, err := src.sync(ctx)
+	if err != nil {
+		head, headErr := src.git(context.Background(), "rev-parse", "HEAD")
+		if headErr != nil {
+			return "", fmt.Errorf("fetching %s: %w", src.Repo, err)
+		}
+		warning := fmt.Sprintf("fetching %s: %v; using last fetched commit %s", src.Repo, err, shortCommit(head))
+		if err := reportWarnings(os.Stderr, []string{warning}); err != nil {
+			return "", err
+		}
+		commit = head
+	}
+	debugf("fetched %s at %s in %s", src.Repo, shortCommit(commit), time.Since(start).Round(time.Millisecond))
+
+	gitConfig = &src
+	return src.File(), nil
+}
+
+// sync shallowly fetches the ref and checks it out, returning the commit. A
+// commit that is already checked out is left alone. The checkout rewrites the
+// config file in place, so it holds reloadMu to keep reloads triggered by other
+// sources from reading a half-written file.
+func (s gitSource) sync(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(s.Dir, ".git")); err != nil {
+		if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+			return "", err
+		}
+		if _, err := s.git(ctx, "init", "--quiet"); err != nil {
+			return "", err
+		}
+	}
+	if _, err := s.git(ctx, "fetch", "--quiet", "--depth", "1", "--end-of-options", s.Repo, s.Ref); err != nil {
+		return "", err
+	}
+	fetched, err := s.git(ctx, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return "", err
+	}
+	if head, err := s.git(ctx, "rev-parse", "HEAD"); err == nil && head == fetched {
+		return head, nil
+	}
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if _, err := s.git(ctx, "checkout", "--quiet", "--force", "--detach", fetched); err != nil {
+		return "", err
+	}
+	return fetched, nil
+}
+
+// git runs a git command in the checkout and returns its trimmed output
+func (s gitSource) git(ctx context.Context, args ...string) (string, error) {
+	bin, err := exec.LookPath("git")
+	if err != nil {
+		return "", errors.New("config is in a git repository but the git binary was not found in PATH")
+	}
+	cmd := exec.CommandContext(ctx, bin, append([]string{"-C", s.Dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// pollGitConfig fetches the config repository every interval until ctx is done,
+// checking out new commits and calling onChange with each of them. Failed polls
+// are passed to warn.
+func pollGitConfig(ctx context.Context, src gitSource, interval time.Duration, onChange func(commit string), warn func(warning string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		before, _ := src.git(ctx, "rev-parse", "HEAD")
+		fetchCtx, cancel := context.WithTimeout(ctx, overlayTimeout)
+		after, err := src.sync(fetchCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				warn(fmt.Sprintf("polling %s: %v", src.Repo, err))
+			}
+			continue
+		}
+		if after != before {
+			fmt.Fprintf(os.Stderr, "Config repository %s updated to %s\n", src.Repo, shortCommit(after))
+			onChange(after)
+		}
+	}
+}
+
+// shortCommit abbreviates a commit hash for messages
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
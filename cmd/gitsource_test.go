@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		raw             string
+		ok, wantErr     bool
+		repo, path, ref string
+	}{
+		{raw: "config.yaml"},
+		{raw: "git+https://host/org/config.git//myapp/config.yaml?ref=main", ok: true,
+			repo: "https://host/org/config.git", path: "myapp/config.yaml", ref: "main"},
+		{raw: "git+ssh://git@host/org/config.git//config.yaml", ok: true,
+			repo: "ssh://git@host/org/config.git", path: "config.yaml", ref: "HEAD"},
+		{raw: "git+file:///srv/config.git//env/prod.yaml?ref=v1.2.0", ok: true,
+			repo: "file:///srv/config.git", path: "env/prod.yaml", ref: "v1.2.0"},
+		{raw: "git+https://host/org/config.git", ok: true, wantErr: true},
+		{raw: "git+host/org/config.git//config.yaml", ok: true, wantErr: true},
+		{raw: "git+--upload-pack=touch /tmp/x;://host//config.yaml", ok: true, wantErr: true},
+		{raw: "git+https://host/org/config.git//config.yaml?ref=--upload-pack=sh", ok: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		src, ok, err := parseGitSource(tt.raw)
+		if ok != tt.ok || (err != nil) != tt.wantErr {
+			t.Errorf("parseGitSource(%q) = ok %v, err %v; want ok %v, error %v", tt.raw, ok, err, tt.ok, tt.wantErr)
+			continue
+		}
+		if src.Repo != tt.repo || src.Path != tt.path || src.Ref != tt.ref {
+			t.Errorf("parseGitSource(%q) = %+v", tt.raw, src)
+		}
+	}
+}
+
+// gitRepo creates a repository with config.yaml holding content and returns
+// its directory and a function committing new content
+func gitRepo(t *testing.T, content string) (string, func(content string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	commit := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "config.yaml")
+		run("commit", "--quiet", "-m", "update config")
+	}
+	run("init", "--quiet")
+	commit(content)
+	return dir, commit
+}
+
+func TestGitSourceSyncAndPoll(t *testing.T) {
+	repo, commit := gitRepo(t, "app:\n  name: first\n")
+	src, _, err := parseGitSource("git+file://" + filepath.ToSlash(repo) + "//config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.Dir = t.TempDir()
+	savedTimeout := overlayTimeout
+	defer func() { overlayTimeout = savedTimeout }()
+	overlayTimeout = 10 * time.Second
+
+	first, err := src.sync(context.Background())
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if data, _ := os.ReadFile(src.File()); string(data) != "app:\n  name: first\n" {
+		t.Errorf("Unexpected checkout content %q", data)
+	}
+	if again, err := src.sync(context.Background()); err != nil || again != first {
+		t.Errorf("Expected an unchanged repository to stay at %s, got %s, %v", first, again, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan string, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pollGitConfig(ctx, src, 20*time.Millisecond, func(commit string) {
+			select {
+			case changes <- commit:
+			default:
+			}
+		}, func(string) {})
+	}()
+	// Stop the poller before overlayTimeout is restored
+	defer func() { cancel(); <-done }()
+
+	commit("app:\n  name: second\n")
+	select {
+	case got := <-changes:
+		if got == first {
+			t.Errorf("Expected a new commit, got %s again", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected polling to pick up the new commit")
+	}
+	if data, _ := os.ReadFile(src.File()); string(data) != "app:\n  name: second\n" {
+		t.Errorf("Expected the new commit to be checked out, got %q", data)
+	}
+}
+
+func TestResolveGitConfigFallsBackToLastCommit(t *testing.T) {
+	repo, _ := gitRepo(t, "app:\n  name: first\n")
+	raw := "git+file://" + filepath.ToSlash(repo) + "//config.yaml"
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(strictWarningsEnv, "")
+	savedTimeout, savedConfig := overlayTimeout, gitConfig
+	defer func() { overlayTimeout, gitConfig = savedTimeout, savedConfig }()
+	overlayTimeout = 10 * time.Second
+
+	path, err := resolveGitConfig(raw)
+	if err != nil {
+		t.Fatalf("resolveGitConfig failed: %v", err)
+	}
+	if err := os.RemoveAll(repo); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := resolveGitConfig(raw); err != nil || again != path {
+		t.Errorf("Expected the last fetched commit at %s, got %s, %v", path, again, err)
+	}
+
+	t.Setenv(strictWarningsEnv, "true")
+	if _, err := resolveGitConfig(raw); err == nil {
+		t.Error("Expected the fallback to fail under strict warnings")
+	}
+}
+
+func TestGitSyncChecksOutUnderReloadMu(t *testing.T) {
+	repo, commit := gitRepo(t, "app:\n  name: first\n")
+	src, _, err := parseGitSource("git+file://" + filepath.ToSlash(repo) + "//config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.Dir = t.TempDir()
+	if _, err := src.sync(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	// A reload in progress holds reloadMu; the new commit must wait for it
+	commit("app:\n  name: second\n")
+	reloadMu.Lock()
+	done := make(chan error, 1)
+	go func() {
+		_, err := src.sync(context.Background())
+		done <- err
+	}()
+	time.Sleep(200 * time.Millisecond)
+	if data, _ := os.ReadFile(src.File()); string(data) != "app:\n  name: first\n" {
+		t.Errorf("Expected the checkout to wait for reloadMu, got %q", data)
+	}
+	reloadMu.Unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if data, _ := os.ReadFile(src.File()); string(data) != "app:\n  name: second\n" {
+		t.Errorf("Expected the new commit to be checked out, got %q", data)
+	}
+}
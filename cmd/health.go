@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Health states reported by /healthz
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthDraining = "draining"
+)
+
+type healthResponse struct {
+	Status string       `json:"status"`
+	Config configHealth `json:"config"`
+}
+
+type configHealth struct {
+	LoadedAt   time.Time     `json:"loaded_at"`
+	Hash       string        `json:"hash"`
+	Sources    sourceSummary `json:"sources"`
+	LastReload *reloadHealth `json:"last_reload,omitempty"`
+}
+
+type reloadHealth struct {
+	At    time.Time `json:"at"`
+	OK    bool      `json:"ok"`
+	Error string    `json:"error,omitempty"`
+}
+
+// health reports the state of the configuration subsystem. The status is degraded
+// when the latest reload was rejected, i.e. the process runs on stale config, and
+// draining once shutdown has begun.
+func (l *liveConfig) health() healthResponse {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	resp := healthResponse{
+		Status: healthOK,
+		Config: configHealth{
+			LoadedAt: l.loadedAt.UTC(),
+			Hash:     l.hash,
+			Sources:  l.sources,
+		},
+	}
+	if !l.lastReloadAt.IsZero() {
+		reload := &reloadHealth{At: l.lastReloadAt.UTC(), OK: l.lastReloadErr == nil}
+		if l.lastReloadErr != nil {
+			resp.Status = healthDegraded
+			reload.Error = l.lastReloadErr.Error()
+		}
+		resp.Config.LastReload = reload
+	}
+	if l.draining {
+		resp.Status = healthDraining
+	}
+	return resp
+}
+
+// healthHandler serves /healthz. Degraded still answers 200 since the process is
+// serving traffic; orchestrators can inspect the body to spot stale config.
+// Draining answers 503 so that load balancers stop sending new requests.
+func healthHandler(live *liveConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := live.health()
+		w.Header().Set("Content-Type", "application/json")
+		if health.Status == healthDraining {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(health)
+	}
+}
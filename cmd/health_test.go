@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+func TestHealthHandler(t *testing.T) {
+	loaded := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name       string
+		live       *liveConfig
+		wantCode   int
+		wantStatus string
+		wantReload *reloadHealth
+	}{
+		{
+			name:       "healthy",
+			live:       &liveConfig{cfg: &config.Config{}, hash: "abc", loadedAt: loaded},
+			wantCode:   http.StatusOK,
+			wantStatus: healthOK,
+		},
+		{
+			name:       "reloaded",
+			live:       &liveConfig{cfg: &config.Config{}, hash: "def", loadedAt: loaded, lastReloadAt: loaded},
+			wantCode:   http.StatusOK,
+			wantStatus: healthOK,
+			wantReload: &reloadHealth{At: loaded, OK: true},
+		},
+		{
+			name:       "degraded after a failed reload",
+			live:       &liveConfig{cfg: &config.Config{}, hash: "abc", loadedAt: loaded, lastReloadAt: loaded.Add(time.Minute), lastReloadErr: errors.New("server.port failed validation")},
+			wantCode:   http.StatusOK,
+			wantStatus: healthDegraded,
+			wantReload: &reloadHealth{At: loaded.Add(time.Minute), OK: false, Error: "server.port failed validation"},
+		},
+		{
+			name:       "draining",
+			live:       &liveConfig{cfg: &config.Config{}, hash: "abc", loadedAt: loaded, draining: true},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: healthDraining,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			healthHandler(tt.live)(rec, httptest.NewRequest("GET", "/healthz", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Expected status code %d, got %d", tt.wantCode, rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Expected JSON content type, got %q", ct)
+			}
+			var body healthResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Invalid body %q: %v", rec.Body.String(), err)
+			}
+			if body.Status != tt.wantStatus {
+				t.Errorf("Expected status %q, got %q", tt.wantStatus, body.Status)
+			}
+			if body.Config.Hash != tt.live.hash || !body.Config.LoadedAt.Equal(loaded) {
+				t.Errorf("Expected hash %q loaded at %s, got %+v", tt.live.hash, loaded, body.Config)
+			}
+			switch {
+			case tt.wantReload == nil && body.Config.LastReload != nil:
+				t.Errorf("Expected no last_reload, got %+v", body.Config.LastReload)
+			case tt.wantReload != nil && (body.Config.LastReload == nil || *body.Config.LastReload != *tt.wantReload):
+				t.Errorf("Expected last_reload %+v, got %+v", tt.wantReload, body.Config.LastReload)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/spf13/cobra"
+)
+
+// keyInfo is the catalog entry of a single configuration key
+type keyInfo struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"`
+	Default     string   `json:"default"`
+	Flag        string   `json:"flag,omitempty"`
+	Shorthand   string   `json:"shorthand,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Env         string   `json:"env"`
+	Validate    string   `json:"validate,omitempty"`
+	Constraints string   `json:"constraints,omitempty"`
+	Secret      bool     `json:"secret"`
+	Deprecated  bool     `json:"deprecated"`
+	Deprecation string   `json:"deprecation,omitempty"`
+}
+
+// keyCatalog describes every configuration key, combining the struct tags with
+// the flag and environment variable bound to each key
+func keyCatalog() []keyInfo {
+	var catalog []keyInfo
+	for _, field := range config.Fields() {
+		info := keyInfo{
+			Key:         field.Key,
+			Type:        field.TypeName(),
+			Default:     defaultValue(field.Key),
+			Env:         envVarName(field.Key),
+			Validate:    field.Validate,
+			Constraints: field.Constraints(),
+			Secret:      field.Secret,
+			Deprecated:  field.Deprecated != "",
+			Deprecation: field.Deprecated,
+		}
+		if flagName, ok := flagBindings[field.Key]; ok {
+			info.Flag = "--" + flagName
+			if f := rootCmd.PersistentFlags().Lookup(flagName); f != nil && f.Shorthand != "" {
+				info.Shorthand = "-" + f.Shorthand
+			}
+			for _, alias := range aliasesOf(flagName) {
+				info.Aliases = append(info.Aliases, "--"+alias)
+			}
+		}
+		catalog = append(catalog, info)
+	}
+	return catalog
+}
+
+var keysFormat string
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "List the catalog of configuration keys",
+	Long: `Lists every configuration key with its type, default, flag, environment variable,
+constraints, and secret and deprecation status. Use --format json to consume the
+catalog from external tools such as UIs, terraform providers, or docs generators.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		catalog := keyCatalog()
+		switch keysFormat {
+		case "json":
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(catalog); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding catalog: %v\n", err)
+				os.Exit(1)
+			}
+		case "table":
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tTYPE\tDEFAULT\tFLAG\tENV\tCONSTRAINTS\tSECRET\tDEPRECATED")
+			for _, k := range catalog {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					k.Key, k.Type, orDash(k.Default), orDash(k.Flag), k.Env, orDash(k.Constraints), yesNo(k.Secret), orDash(k.Deprecation))
+			}
+			w.Flush()
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown format %q (expected table or json)\n", keysFormat)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	keysCmd.Flags().StringVar(&keysFormat, "format", "table", "output format: table or json")
+	keysCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	configCmd.AddCommand(keysCmd)
+}
+
+// yesNo renders a boolean table cell
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runKeys runs config keys in a format and returns its output
+func runKeys(t *testing.T, format string) string {
+	t.Helper()
+	saved := keysFormat
+	defer func() { keysFormat = saved }()
+	keysFormat = format
+
+	var out bytes.Buffer
+	keysCmd.SetOut(&out)
+	defer keysCmd.SetOut(nil)
+	keysCmd.Run(keysCmd, nil)
+	return out.String()
+}
+
+func TestKeysJSON(t *testing.T) {
+	var catalog []keyInfo
+	if err := json.Unmarshal([]byte(runKeys(t, "json")), &catalog); err != nil {
+		t.Fatalf("Invalid JSON catalog: %v", err)
+	}
+	byKey := map[string]keyInfo{}
+	for _, k := range catalog {
+		byKey[k.Key] = k
+	}
+
+	tests := []keyInfo{
+		{Key: "server.port", Type: "int", Default: "0", Flag: "--server-port", Shorthand: "-p", Env: "MYAPP_SERVER_PORT",
+			Validate: "gte=1024,lte=9000", Constraints: ">= 1024, <= 9000"},
+		{Key: "server.shutdown.grace_period", Type: "duration", Default: "30s", Flag: "--server-shutdown-grace-period",
+			Env: "MYAPP_SERVER_SHUTDOWN_GRACE_PERIOD", Validate: "gtefield=DrainTimeout", Constraints: ">= DrainTimeout"},
+		{Key: "database.host", Type: "string", Flag: "--db-host", Aliases: []string{"--db_host"}, Env: "MYAPP_DATABASE_HOST"},
+		{Key: "database.password", Type: "string", Flag: "--db-password", Env: "MYAPP_DATABASE_PASSWORD", Secret: true},
+	}
+	for _, want := range tests {
+		if got := byKey[want.Key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("Catalog entry for %s:\n got %+v\nwant %+v", want.Key, got, want)
+		}
+	}
+	if len(catalog) != len(keyCatalog()) {
+		t.Errorf("Expected %d entries, got %d", len(keyCatalog()), len(catalog))
+	}
+}
+
+func TestKeysTable(t *testing.T) {
+	lines := strings.Split(strings.TrimRight(runKeys(t, "table"), "\n"), "\n")
+	if header := strings.Fields(lines[0]); !reflect.DeepEqual(header, []string{"KEY", "TYPE", "DEFAULT", "FLAG", "ENV", "CONSTRAINTS", "SECRET", "DEPRECATED"}) {
+		t.Errorf("Unexpected header %q", lines[0])
+	}
+	if len(lines)-1 != len(keyCatalog()) {
+		t.Errorf("Expected one row per key, got %d rows", len(lines)-1)
+	}
+
+	rows := map[string][]string{}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		rows[fields[0]] = fields
+	}
+	want := map[string][]string{
+		"app.name":          {"app.name", "string", "-", "--app-name", "MYAPP_APP_NAME", "required", "no", "-"},
+		"database.password": {"database.password", "string", "-", "--db-password", "MYAPP_DATABASE_PASSWORD", "-", "yes", "-"},
+	}
+	for key, fields := range want {
+		if !reflect.DeepEqual(rows[key], fields) {
+			t.Errorf("Row for %s = %q, want %q", key, rows[key], fields)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/example/cobra-viper-demo/kube"
+)
+
+var (
+	// kubeConfigMap and kubeSecret name the objects read through the Kubernetes
+	// API, as "name" or "namespace/name"
+	kubeConfigMap string
+	kubeSecret    string
+
+	// kubeKeys holds the viper keys provided by Kubernetes objects
+	kubeKeys = map[string]bool{}
+
+	// kubeLoadErrs holds the error of the last fetch of each object, which fails
+	// the next configuration load instead of silently dropping its settings
+	kubeLoadErrs = map[string]error{}
+
+	// kubeVersions holds the resource version of each object as last fetched, so
+	// watches start from the loaded version
+	kubeVersions   = map[string]string{}
+	kubeVersionsMu sync.Mutex
+
+	kubeClient    *kube.Client
+	kubeClientErr error
+	kubeOnce      sync.Once
+)
+
+// kubeObject is a ConfigMap or Secret used as a configuration source
+type kubeObject struct {
+	kind string
+	ref  string
+}
+
+// name identifies the object in messages and the load profile
+func (o kubeObject) name() string {
+	return strings.TrimSuffix(o.kind, "s") + "/" + o.ref
+}
+
+// kubeObjects lists the configured objects; the Secret overrides the ConfigMap
+func kubeObjects() []kubeObject {
+	var objects []kubeObject
+	if kubeConfigMap != "" {
+		objects = append(objects, kubeObject{kube.ConfigMap, kubeConfigMap})
+	}
+	if kubeSecret != "" {
+		objects = append(objects, kubeObject{kube.Secret, kubeSecret})
+	}
+	return objects
+}
+
+// kubeAPI returns the in-cluster API client, created on first use
+func kubeAPI() (*kube.Client, error) {
+	kubeOnce.Do(func() {
+		kubeClient, kubeClientErr = kube.InCluster()
+	})
+	return kubeClient, kubeClientErr
+}
+
+// kubeOverlays reads the configured ConfigMap and Secret through the Kubernetes
+// API. Viper keeps their settings in the config layer, so env vars and flags
+// still take precedence. The config cache only records the Secret's resource
+// version.
+func kubeOverlays() []overlaySource {
+	kubeKeys = map[string]bool{}
+	var sources []overlaySource
+	for _, obj := range kubeObjects() {
+		src := overlaySource{
+			name: obj.name(),
+			fetch: func(ctx context.Context) (map[string]any, error) {
+				return fetchKubeObject(ctx, obj)
+			},
+			apply: func(settings map[string]any, err error) {
+				applyKubeObject(obj, settings, err)
+			},
+		}
+		if obj.kind == kube.Secret {
+			src.secretVersion = func() string {
+				kubeVersionsMu.Lock()
+				defer kubeVersionsMu.Unlock()
+				return kubeVersions[obj.name()]
+			}
+		}
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+// fetchKubeObject reads an object and converts its data into settings
+func fetchKubeObject(ctx context.Context, obj kubeObject) (map[string]any, error) {
+	client, err := kubeAPI()
+	if err != nil {
+		return nil, err
+	}
+	o, err := client.Get(ctx, obj.kind, obj.ref)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", obj.name(), err)
+	}
+	kubeVersionsMu.Lock()
+	kubeVersions[obj.name()] = o.ResourceVersion
+	kubeVersionsMu.Unlock()
+
+	settings, err := kubeSettings(o.Data)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", obj.name(), err)
+	}
+	return settings, nil
+}
+
+// applyKubeObject merges an object's settings on top of the config file
+func applyKubeObject(obj kubeObject, settings map[string]any, err error) {
+	kubeLoadErrs[obj.name()] = err
+	if err != nil || len(settings) == 0 {
+		return
+	}
+	if err := v.MergeConfigMap(settings); err != nil {
+		kubeLoadErrs[obj.name()] = fmt.Errorf("merging %s: %w", obj.name(), err)
+		return
+	}
+	collectKeys("", settings, kubeKeys)
+	fmt.Fprintf(os.Stderr, "Using Kubernetes %s\n", obj.name())
+}
+
+// kubeLoadErr returns the fetch errors of the configured objects
+func kubeLoadErr() error {
+	objects := kubeObjects()
+	if len(objects) == 0 {
+		return nil
+	}
+	if _, err := kubeAPI(); err != nil {
+		return err
+	}
+	var errs []error
+	for _, obj := range objects {
+		errs = append(errs, kubeLoadErrs[obj.name()])
+	}
+	return errors.Join(errs...)
+}
+
+// kubeSettings converts the data of a ConfigMap or Secret into settings. Entries
+// named after a config file, such as config.yaml, are parsed in that format and
+// merged in name order; any other entry is a single dotted key such as
+// database.host, which overrides the files.
+func kubeSettings(data map[string]string) (map[string]any, error) {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	registry := newCodecRegistry()
+	settings := map[string]any{}
+	var keys []string
+	for _, name := range names {
+		format := strings.TrimPrefix(filepath.Ext(name), ".")
+		decoder, err := registry.Decoder(format)
+		if format == "" || err != nil {
+			keys = append(keys, name)
+			continue
+		}
+		doc := map[string]any{}
+		if err := decoder.Decode([]byte(data[name]), doc); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		mergeValues(settings, doc)
+	}
+	for _, key := range keys {
+		parts := strings.Split(strings.ToLower(key), ".")
+		m := settings
+		for _, part := range parts[:len(parts)-1] {
+			nested, ok := m[part].(map[string]any)
+			if !ok {
+				nested = map[string]any{}
+				m[part] = nested
+			}
+			m = nested
+		}
+		m[parts[len(parts)-1]] = data[key]
+	}
+	return settings, nil
+}
+
+// watchKubeObjects watches the configured objects until ctx is done, calling
+// onChange with the object name whenever one changes and passing watch errors
+// to warn
+func watchKubeObjects(ctx context.Context, onChange func(trigger string), warn func(warning string)) {
+	objects := kubeObjects()
+	if len(objects) == 0 {
+		return
+	}
+	client, err := kubeAPI()
+	if err != nil {
+		return
+	}
+	for _, obj := range objects {
+		kubeVersionsMu.Lock()
+		version := kubeVersions[obj.name()]
+		kubeVersionsMu.Unlock()
+		go client.Watch(ctx, obj.kind, obj.ref, version,
+			func() { onChange(obj.name()) },
+			func(err error) { warn(fmt.Sprintf("watching %s: %v", obj.name(), err)) })
+		fmt.Fprintf(os.Stderr, "Watching Kubernetes %s\n", obj.name())
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/example/cobra-viper-demo/kube"
+)
+
+func TestKubeSettings(t *testing.T) {
+	settings, err := kubeSettings(map[string]string{
+		"config.yaml":   "server:\n  port: 8080\n  host: 0.0.0.0\n",
+		"override.json": `{"server": {"port": 9000}}`,
+		"server.host":   "10.0.0.1",
+		"Database.Name": "orders",
+	})
+	if err != nil {
+		t.Fatalf("kubeSettings failed: %v", err)
+	}
+	want := map[string]any{
+		"server":   map[string]any{"port": float64(9000), "host": "10.0.0.1"},
+		"database": map[string]any{"name": "orders"},
+	}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("kubeSettings = %#v, want %#v", settings, want)
+	}
+
+	if _, err := kubeSettings(map[string]string{"config.yaml": "server: ["}); err == nil {
+		t.Error("Expected an error for a malformed config file entry")
+	}
+}
+
+func TestKubeOverlaysMarkSecrets(t *testing.T) {
+	savedConfigMap, savedSecret := kubeConfigMap, kubeSecret
+	defer func() { kubeConfigMap, kubeSecret = savedConfigMap, savedSecret }()
+	kubeConfigMap, kubeSecret = "myapp", "prod/myapp"
+
+	kubeVersionsMu.Lock()
+	kubeVersions[kubeObject{kube.Secret, "prod/myapp"}.name()] = "42"
+	kubeVersionsMu.Unlock()
+
+	sources := kubeOverlays()
+	if len(sources) != 2 || sources[0].secretVersion != nil || sources[1].secretVersion == nil {
+		t.Fatalf("Expected only the Secret to be marked as a secret source")
+	}
+	if got := sources[1].secretVersion(); got != "42" {
+		t.Errorf("Expected the Secret's resource version, got %q", got)
+	}
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.yaml.in/yaml/v3"
+)
+
+// localeEnvVars are consulted in order for the help language when --lang is not
+// given, following the usual POSIX precedence after our own variable
+var localeEnvVars = []string{envPrefix + "_LANG", "LC_ALL", "LC_MESSAGES", "LANG"}
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// helpLang is the --lang flag. Help is printed before flags are parsed into
+// viper, so the language is picked from the raw arguments by requestedLanguage.
+var helpLang string
+
+// catalog holds the translated help text of one language. Entries missing from
+// a catalog keep their English text.
+type catalog struct {
+	// Usage maps headings of the usage template, such as "Flags:", to translations
+	Usage map[string]string `yaml:"usage"`
+	// Generated maps text that cobra and pflag generate, such as "[flags]" in
+	// usage lines and "(default " in flag usages, to translations
+	Generated map[string]string `yaml:"generated"`
+	// HelpFlag is the usage of -h/--help; %s is the command name
+	HelpFlag string `yaml:"help_flag"`
+	// Commands is keyed by command path without the program name, "" for the root
+	Commands map[string]commandText `yaml:"commands"`
+}
+
+// commandText is the translated text of one command; %[1]s in Short and Long
+// stands for the program name
+type commandText struct {
+	Short string            `yaml:"short"`
+	Long  string            `yaml:"long"`
+	Flags map[string]string `yaml:"flags"`
+}
+
+// languages lists the languages with a catalog, sorted
+func languages() []string {
+	entries, _ := locales.ReadDir("locales")
+	var langs []string
+	for _, e := range entries {
+		langs = append(langs, strings.TrimSuffix(e.Name(), path.Ext(e.Name())))
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// loadCatalog reads the embedded catalog of a language
+func loadCatalog(lang string) (*catalog, error) {
+	data, err := locales.ReadFile("locales/" + lang + ".yaml")
+	if err != nil {
+		return nil, err
+	}
+	var cat catalog
+	if err := yaml.Unmarshal(data, &cat); err != nil {
+		return nil, fmt.Errorf("parsing %s catalog: %w", lang, err)
+	}
+	return &cat, nil
+}
+
+// requestedLanguage returns the language asked for by --lang in args, or else by
+// the first locale environment variable set. explicit is set for --lang.
+func requestedLanguage(args []string, getenv func(string) string) (lang string, explicit bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--lang="); ok {
+			return value, true
+		}
+		if arg == "--lang" && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	for _, name := range localeEnvVars {
+		if value := getenv(name); value != "" {
+			return value, false
+		}
+	}
+	return "", false
+}
+
+// matchLanguage maps a locale name such as es_ES.UTF-8 or es-MX to the
+// catalog serving it, trying the full name and then the base language. ok is
+// false when no catalog matches; English needs none.
+func matchLanguage(locale string) (lang string, ok bool) {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "-", "_")
+	base, _, _ := strings.Cut(locale, "_")
+	available := languages()
+	for _, candidate := range []string{locale, strings.ToLower(base)} {
+		if i := sort.SearchStrings(available, candidate); i < len(available) && available[i] == candidate {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// isEnglish reports whether a locale selects the built-in English text
+func isEnglish(locale string) bool {
+	base, _, _ := strings.Cut(strings.ToLower(locale), "_")
+	base, _, _ = strings.Cut(base, "-")
+	base, _, _ = strings.Cut(base, ".")
+	return base == "" || base == "en" || base == "c" || base == "posix"
+}
+
+// localizeHelp translates the help text of the command tree into the language
+// requested by --lang or the locale. It runs before the command executes,
+// because help is printed before any initializer does. A missing translation is
+// a warning; since flags are not parsed yet, it counts as an early warning that
+// --strict-warnings fails on once they are.
+func localizeHelp(root *cobra.Command, args []string) error {
+	locale, explicit := requestedLanguage(args, os.Getenv)
+	if isEnglish(locale) {
+		return nil
+	}
+	lang, ok := matchLanguage(locale)
+	if !ok {
+		if explicit {
+			return earlyWarning(fmt.Sprintf("no translation for language %q, available: en, %s", locale, strings.Join(languages(), ", ")))
+		}
+		return nil
+	}
+	cat, err := loadCatalog(lang)
+	if err != nil {
+		return earlyWarning(err.Error())
+	}
+	applyCatalog(root, cat)
+	return nil
+}
+
+// applyCatalog replaces the help text of every command, including the help
+// command, the headings of the usage template, and the text cobra and pflag
+// generate for it with their translations
+func applyCatalog(root *cobra.Command, cat *catalog) {
+	root.InitDefaultHelpCmd()
+	walkCommands(root, func(c *cobra.Command) {
+		text := cat.Commands[commandKey(c)]
+		if text.Short != "" {
+			c.Short = withProgram(text.Short, root.Name())
+		}
+		if text.Long != "" {
+			c.Long = withProgram(text.Long, root.Name())
+		}
+		for _, flags := range []*pflag.FlagSet{c.PersistentFlags(), c.Flags()} {
+			flags.VisitAll(func(f *pflag.Flag) {
+				if usage, ok := text.Flags[f.Name]; ok {
+					f.Usage = usage
+				}
+			})
+		}
+		if cat.HelpFlag != "" && c.Flags().Lookup("help") == nil {
+			// Predefined so that cobra does not add its English one
+			c.Flags().BoolP("help", "h", false, fmt.Sprintf(cat.HelpFlag, c.Name()))
+			_ = c.Flags().SetAnnotation("help", cobra.FlagSetByCobraAnnotation, []string{"true"})
+		}
+	})
+	if len(cat.Usage) > 0 || len(cat.Generated) > 0 {
+		cobra.AddTemplateFunc("localize", func(s string) string {
+			return translateText(s, cat.Generated)
+		})
+		tmpl := translateText(root.UsageTemplate(), cat.Usage)
+		tmpl = strings.NewReplacer(
+			"{{.UseLine}}", "{{.UseLine | localize}}",
+			"FlagUsages | trimTrailingWhitespaces", "FlagUsages | localize | trimTrailingWhitespaces",
+		).Replace(tmpl)
+		root.SetUsageTemplate(tmpl)
+	}
+}
+
+// walkCommands calls fn for c and all of its descendants
+func walkCommands(c *cobra.Command, fn func(*cobra.Command)) {
+	fn(c)
+	for _, sub := range c.Commands() {
+		walkCommands(sub, fn)
+	}
+}
+
+// commandKey returns the catalog key of a command: its path without the
+// program name
+func commandKey(c *cobra.Command) string {
+	_, key, _ := strings.Cut(c.CommandPath(), " ")
+	return key
+}
+
+// withProgram substitutes the program name for %[1]s
+func withProgram(text, program string) string {
+	return strings.ReplaceAll(text, "%[1]s", program)
+}
+
+// translateText replaces English text with its translations, longest first so
+// that "Global Flags:" wins over "Flags:"
+func translateText(text string, translations map[string]string) string {
+	olds := make([]string, 0, len(translations))
+	for old := range translations {
+		olds = append(olds, old)
+	}
+	sort.Slice(olds, func(i, j int) bool {
+		if len(olds[i]) != len(olds[j]) {
+			return len(olds[i]) > len(olds[j])
+		}
+		return olds[i] < olds[j]
+	})
+	pairs := make([]string, 0, 2*len(olds))
+	for _, old := range olds {
+		pairs = append(pairs, old, translations[old])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
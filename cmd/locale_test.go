@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// TestCatalogsMatchCommands checks that every catalog translates every command
+// and visible flag, and has no entries for commands or flags that are gone
+func TestCatalogsMatchCommands(t *testing.T) {
+	rootCmd.InitDefaultHelpCmd()
+	commands := map[string]*cobra.Command{}
+	walkCommands(rootCmd, func(c *cobra.Command) {
+		if !c.Hidden {
+			commands[commandKey(c)] = c
+		}
+	})
+
+	for _, lang := range languages() {
+		cat, err := loadCatalog(lang)
+		if err != nil {
+			t.Fatalf("loadCatalog(%q) failed: %v", lang, err)
+		}
+		for key, text := range cat.Commands {
+			c, ok := commands[key]
+			if !ok {
+				t.Errorf("%s: catalog entry for unknown command %q", lang, key)
+				continue
+			}
+			for name := range text.Flags {
+				if c.PersistentFlags().Lookup(name) == nil && c.LocalNonPersistentFlags().Lookup(name) == nil {
+					t.Errorf("%s: catalog entry for unknown flag --%s of %q", lang, name, key)
+				}
+			}
+		}
+		for key, c := range commands {
+			text := cat.Commands[key]
+			if text.Short == "" {
+				t.Errorf("%s: no translation of the short description of %q", lang, key)
+			}
+			if c.Long != "" && text.Long == "" {
+				t.Errorf("%s: no translation of the long description of %q", lang, key)
+			}
+			for _, flags := range []*pflag.FlagSet{c.PersistentFlags(), c.LocalNonPersistentFlags()} {
+				flags.VisitAll(func(f *pflag.Flag) {
+					if _, ok := text.Flags[f.Name]; !ok && !f.Hidden && f.Name != "help" {
+						t.Errorf("%s: no translation of flag --%s of %q", lang, f.Name, key)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestRequestedLanguage(t *testing.T) {
+	env := map[string]string{"LC_ALL": "", "LANG": "es_ES.UTF-8"}
+	getenv := func(name string) string { return env[name] }
+
+	tests := []struct {
+		args         []string
+		wantLang     string
+		wantExplicit bool
+	}{
+		{[]string{"serve", "--lang", "de"}, "de", true},
+		{[]string{"--lang=fr", "--help"}, "fr", true},
+		{[]string{"serve"}, "es_ES.UTF-8", false},
+		{[]string{"--", "--lang", "de"}, "es_ES.UTF-8", false},
+	}
+	for _, tt := range tests {
+		lang, explicit := requestedLanguage(tt.args, getenv)
+		if lang != tt.wantLang || explicit != tt.wantExplicit {
+			t.Errorf("requestedLanguage(%q) = %q, %v, want %q, %v", tt.args, lang, explicit, tt.wantLang, tt.wantExplicit)
+		}
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+		ok     bool
+	}{
+		{"es", "es", true},
+		{"es_ES.UTF-8", "es", true},
+		{"es-MX", "es", true},
+		{"ES", "es", true},
+		{"xx_YY", "", false},
+	}
+	for _, tt := range tests {
+		lang, ok := matchLanguage(tt.locale)
+		if lang != tt.want || ok != tt.ok {
+			t.Errorf("matchLanguage(%q) = %q, %v, want %q, %v", tt.locale, lang, ok, tt.want, tt.ok)
+		}
+	}
+	for _, locale := range []string{"", "C", "POSIX", "en_US.UTF-8", "en-GB"} {
+		if !isEnglish(locale) {
+			t.Errorf("isEnglish(%q) = false, want true", locale)
+		}
+	}
+}
+
+func TestApplyCatalog(t *testing.T) {
+	root := &cobra.Command{Use: "demo", Short: "Demo"}
+	child := &cobra.Command{Use: "child", Short: "Child", Run: func(*cobra.Command, []string) {}}
+	child.Flags().String("name", "x", "the name")
+	root.AddCommand(child)
+
+	applyCatalog(root, &catalog{
+		Usage:     map[string]string{"Usage:": "Uso:", "Flags:": "Opciones:", "Global Flags:": "Opciones globales:"},
+		Generated: map[string]string{"[flags]": "[opciones]", "(default ": "(por defecto "},
+		HelpFlag:  "ayuda de %s",
+		Commands: map[string]commandText{
+			"child": {Short: "Hijo", Long: "Ejecute %[1]s child.", Flags: map[string]string{"name": "el nombre"}},
+		},
+	})
+
+	var out bytes.Buffer
+	child.SetOut(&out)
+	if err := child.Help(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Ejecute demo child.", "Uso:", "demo child [opciones]", "Opciones:", `el nombre (por defecto "x")`, "ayuda de child"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in help:\n%s", want, out.String())
+		}
+	}
+	if root.Short != "Demo" {
+		t.Errorf("Expected untranslated root text to be kept, got %q", root.Short)
+	}
+}
+
+func TestLocalizeHelpStrictWarnings(t *testing.T) {
+	saved := earlyWarnings
+	defer func() { earlyWarnings = saved }()
+	root := &cobra.Command{Use: "app"}
+
+	t.Setenv(strictWarningsEnv, "false")
+	if err := localizeHelp(root, []string{"--lang", "xx"}); err != nil {
+		t.Errorf("Expected a missing translation to be a warning, got %v", err)
+	}
+	if earlyWarnings != saved+1 {
+		t.Errorf("Expected the warning to be counted for --strict-warnings, got %d", earlyWarnings-saved)
+	}
+
+	t.Setenv(strictWarningsEnv, "true")
+	var warningsErr *warningsError
+	if err := localizeHelp(root, []string{"--lang", "xx"}); !errors.As(err, &warningsErr) {
+		t.Errorf("Expected a warningsError under strict warnings, got %v", err)
+	}
+}
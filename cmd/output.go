@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+// Output formats accepted by --output; templates are given inline after
+// go-template= or read from the file after go-template-file=
+const (
+	outputJSON           = "json"
+	outputYAML           = "yaml"
+	outputFlat           = "flat"
+	outputGoTemplate     = "go-template"
+	outputGoTemplateFile = "go-template-file"
+)
+
+// outputUsage describes the --output values
+const outputUsage = "output format: json, yaml, flat, go-template=<template>, or go-template-file=<file>"
+
+// outputFormats lists the --output values offered by shell completion
+var outputFormats = []string{outputJSON, outputYAML, outputFlat, outputGoTemplate + "=", outputGoTemplateFile + "="}
+
+// printer writes a value in one output format
+type printer func(w io.Writer, value any) error
+
+// newPrinter returns the printer for an --output value. Templates are parsed
+// here, so a broken template is reported before the configuration is loaded.
+func newPrinter(output string) (printer, error) {
+	name, arg, hasArg := strings.Cut(output, "=")
+	switch name {
+	case outputJSON:
+		return printJSON, nil
+	case outputYAML:
+		return printYAML, nil
+	case outputFlat:
+		return printFlat, nil
+	case outputGoTemplate, outputGoTemplateFile:
+		if !hasArg || arg == "" {
+			return nil, fmt.Errorf("--output %s requires a template, as in %s=...", name, name)
+		}
+		text := arg
+		if name == outputGoTemplateFile {
+			data, err := os.ReadFile(arg)
+			if err != nil {
+				return nil, fmt.Errorf("reading template: %w", err)
+			}
+			text = string(data)
+		}
+		tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template: %w", err)
+		}
+		return func(w io.Writer, value any) error {
+			return tmpl.Execute(w, value)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (expected json, yaml, flat, go-template=..., or go-template-file=...)", output)
+}
+
+// mustNewPrinter is newPrinter, exiting on an invalid --output value
+func mustNewPrinter(output string) printer {
+	p, err := newPrinter(output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return p
+}
+
+func printJSON(w io.Writer, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// printYAML writes a configuration, or part of one, with the keys viper reads
+func printYAML(w io.Writer, value any) error {
+	if cfg, ok := value.(*config.Config); ok {
+		value = *cfg
+	}
+	data, err := config.MarshalYAMLValue(value)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// printFlat writes a configuration as sorted key=value lines, the easiest shape
+// to grep and diff
+func printFlat(w io.Writer, value any) error {
+	cfg, ok := value.(*config.Config)
+	if !ok {
+		return fmt.Errorf("flat output needs the whole configuration, got %T", value)
+	}
+	for _, line := range flatLines(cfg, "") {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flatLines renders the settings of cfg, extensions included, as sorted
+// key=value lines. With a prefix, only that key and the keys below it are kept.
+// Secrets are printed as they are in cfg; callers redact them with --secrets.
+func flatLines(cfg *config.Config, prefix string) []string {
+	settings := map[string]any{}
+	for _, s := range cfg.Settings() {
+		settings[s.Key] = s.Value
+	}
+	flattenSettings("extensions", cfg.Extensions, settings)
+
+	var lines []string
+	for key, value := range settings {
+		if prefix != "" && key != prefix && !strings.HasPrefix(key, prefix+".") {
+			continue
+		}
+		text := fmt.Sprint(value)
+		if list, ok := value.([]any); ok {
+			data, _ := json.Marshal(list)
+			text = string(data)
+		}
+		lines = append(lines, key+"="+text)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// mustPrint writes value with p, exiting on failures such as a template
+// referencing a missing field
+func mustPrint(w io.Writer, p printer, value any) {
+	if err := p(w, value); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+func TestNewPrinter(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.Host = "localhost"
+	cfg.Server.Port = 8080
+	cfg.Server.Shutdown.GracePeriod = 30 * time.Second
+
+	tmplFile := filepath.Join(t.TempDir(), "out.tmpl")
+	if err := os.WriteFile(tmplFile, []byte("{{.Server.Port}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		output string
+		value  any
+		want   string
+	}{
+		{"go-template={{.Server.Host}}:{{.Server.Port}}", cfg, "localhost:8080"},
+		{"go-template=" + "{{.Host}}", cfg.Server, "localhost"},
+		{"go-template-file=" + tmplFile, cfg, "8080"},
+		{"json", cfg.Server.Port, "8080\n"},
+		{"yaml", cfg.Server.Shutdown, "grace_period: 30s\ndrain_timeout: 0s\n"},
+	}
+	for _, tt := range tests {
+		p, err := newPrinter(tt.output)
+		if err != nil {
+			t.Fatalf("newPrinter(%q) failed: %v", tt.output, err)
+		}
+		var out bytes.Buffer
+		if err := p(&out, tt.value); err != nil {
+			t.Fatalf("printing with %q failed: %v", tt.output, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("printing with %q = %q, want %q", tt.output, out.String(), tt.want)
+		}
+	}
+
+	for _, output := range []string{"xml", "go-template", "go-template=", "go-template={{", "go-template-file=/nonexistent"} {
+		if _, err := newPrinter(output); err == nil {
+			t.Errorf("Expected newPrinter(%q) to fail", output)
+		}
+	}
+
+	if _, err := newPrinter("flat"); err != nil {
+		t.Fatalf("newPrinter(flat) failed: %v", err)
+	}
+
+	p, _ := newPrinter("go-template={{.Nope}}")
+	if err := p(&bytes.Buffer{}, cfg); err == nil || !strings.Contains(err.Error(), "Nope") {
+		t.Errorf("Expected an error for a missing field, got %v", err)
+	}
+}
+
+func TestFlatLines(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.Port = 8080
+	cfg.Server.Shutdown.GracePeriod = 30 * time.Second
+	cfg.Database.Password = "s3cret"
+	cfg.Extensions = map[string]any{"cache": map[string]any{"nodes": []any{"a", "b"}}}
+
+	lines := flatLines(secretsOptions{secrets: secretsRedact}.scrub(cfg), "")
+	for _, want := range []string{"server.port=8080", "server.shutdown.grace_period=30s", "database.password=" + config.Redacted, "database.host=", `extensions.cache.nodes=["a","b"]`} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("Expected line %q in %q", want, lines)
+		}
+	}
+	if !slices.IsSorted(lines) {
+		t.Errorf("Expected sorted lines, got %q", lines)
+	}
+
+	lines = flatLines(cfg, "server.shutdown")
+	want := []string{"server.shutdown.drain_timeout=0s", "server.shutdown.grace_period=30s"}
+	if !slices.Equal(lines, want) {
+		t.Errorf("flatLines(server.shutdown) = %q, want %q", lines, want)
+	}
+}
+
+func TestDisplayRedactsSecretsInEveryFormat(t *testing.T) {
+	useViper(t, map[string]any{"app.name": "demo", "server.port": 8080, "database.password": "s3cret"})
+	savedOutput, savedSecrets := displayOutput, displaySecrets
+	defer func() { displayOutput, displaySecrets = savedOutput, savedSecrets }()
+
+	formats := []string{outputJSON, outputYAML, outputFlat, "go-template={{.Database.Password}}"}
+	for _, format := range formats {
+		displayOutput = format
+		displaySecrets = secretsOptions{secrets: secretsRedact}
+		var out bytes.Buffer
+		displayConfiguration(&out)
+		if strings.Contains(out.String(), "s3cret") || !strings.Contains(out.String(), config.Redacted) {
+			t.Errorf("Expected the password to be redacted in %s output:\n%s", format, out.String())
+		}
+
+		displaySecrets = secretsOptions{secrets: secretsInclude, confirmSecrets: true}
+		out.Reset()
+		displayConfiguration(&out)
+		if !strings.Contains(out.String(), "s3cret") {
+			t.Errorf("Expected --secrets include --confirm-secrets to show the password in %s output:\n%s", format, out.String())
+		}
+	}
+}
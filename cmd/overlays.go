@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// overlayTimeout bounds how long fetching all overlay sources may take
+	overlayTimeout time.Duration
+
+	// debugOutput enables diagnostic messages such as source load timings
+	debugOutput bool
+)
+
+// overlaySource is a configuration source that viper keeps in its config layer,
+// on top of the config file. fetch runs concurrently with the other sources;
+// apply merges its result into viper and runs in declaration order. The optional
+// fingerprint cheaply identifies the source's current content, letting the
+// config cache skip fetch when it is unchanged. secretVersion marks a source of
+// credentials: it is fetched on every load, and the config cache only records
+// the version it returns after a fetch, never the settings. encrypted reports
+// whether the last fetch decrypted its input, in which case the load is not
+// cached at all.
+type overlaySource struct {
+	name          string
+	fetch         func(ctx context.Context) (map[string]any, error)
+	apply         func(settings map[string]any, err error)
+	fingerprint   func() (string, error)
+	secretVersion func() string
+	encrypted     func() bool
+}
+
+// overlaySources lists the overlay sources in merge order; later sources
+// override earlier ones
+func overlaySources() []overlaySource {
+	sources := []overlaySource{registryOverlay()}
+	sources = append(sources, kubeOverlays()...)
+	return append(sources, extensionsOverlay())
+}
+
+// mergeOverlays layers the overlay sources on top of the config file. They are
+// fetched concurrently under a single overlayTimeout deadline, so startup takes
+// as long as the slowest source rather than the sum, and then merged in a fixed
+// order so the result does not depend on which source finished first. It must
+// run again after every re-read of the config file, which replaces that layer.
+func mergeOverlays() {
+	beginConfigCache()
+	sources := overlaySources()
+	results := make([]overlayResult, len(sources))
+	fingerprints := make([]string, len(sources))
+
+	start := time.Now()
+	var pending []overlaySource
+	var pendingIdx []int
+	for i, src := range sources {
+		if src.fingerprint != nil {
+			if fp, err := src.fingerprint(); err == nil {
+				fingerprints[i] = fp
+			}
+		}
+		if settings, ok := cachedOverlay(src.name, fingerprints[i]); ok && src.secretVersion == nil {
+			debugf("using cached %s", src.name)
+			results[i] = overlayResult{settings: settings}
+			continue
+		}
+		pending = append(pending, src)
+		pendingIdx = append(pendingIdx, i)
+	}
+	fetched := map[int]bool{}
+	for j, res := range fetchOverlays(pending, overlayTimeout) {
+		results[pendingIdx[j]] = res
+		fetched[pendingIdx[j]] = true
+	}
+	recordPhase(phaseOverlayFetch, start)
+	for i, src := range sources {
+		recordSource(src.name, results[i].elapsed, !fetched[i])
+	}
+
+	start = time.Now()
+	for i, src := range sources {
+		src.apply(results[i].settings, results[i].err)
+		switch {
+		case results[i].err != nil:
+		case src.encrypted != nil && src.encrypted():
+			skipConfigCache(src.name + " is encrypted")
+		case src.secretVersion != nil:
+			recordSecretOverlay(src.name, src.secretVersion())
+		default:
+			recordOverlay(src.name, fingerprints[i], results[i].settings)
+		}
+	}
+	recordPhase(phaseOverlayMerge, start)
+}
+
+// overlayResult is the outcome of fetching a single overlay source
+type overlayResult struct {
+	settings map[string]any
+	err      error
+	elapsed  time.Duration
+}
+
+// fetchOverlays fetches every source concurrently. A source that has not
+// finished when the deadline expires yields a timeout error.
+func fetchOverlays(sources []overlaySource, timeout time.Duration) []overlayResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	results := make([]overlayResult, len(sources))
+	var wg sync.WaitGroup
+	for i, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = fetchOverlay(ctx, src, timeout)
+		}()
+	}
+	wg.Wait()
+
+	var sum time.Duration
+	for i, src := range sources {
+		debugf("fetched %s in %s", src.name, results[i].elapsed.Round(time.Microsecond))
+		sum += results[i].elapsed
+	}
+	debugf("fetched %d overlay source(s) in %s (%s sequentially)", len(sources), time.Since(start).Round(time.Microsecond), sum.Round(time.Microsecond))
+	return results
+}
+
+func fetchOverlay(ctx context.Context, src overlaySource, timeout time.Duration) overlayResult {
+	start := time.Now()
+	// Buffered so the fetch goroutine can exit after a timeout; fetch gets ctx
+	// so it can stop early
+	done := make(chan overlayResult, 1)
+	go func() {
+		settings, err := src.fetch(ctx)
+		done <- overlayResult{settings: settings, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		res.elapsed = time.Since(start)
+		return res
+	case <-ctx.Done():
+		return overlayResult{err: fmt.Errorf("timed out after %s: %w", timeout, ctx.Err()), elapsed: time.Since(start)}
+	}
+}
+
+// debugf prints a diagnostic message to stderr when --debug is set
+func debugf(format string, args ...any) {
+	if debugOutput {
+		fmt.Fprintf(os.Stderr, "debug: "+format+"\n", args...)
+	}
+}
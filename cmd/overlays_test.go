@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func sleepySource(name string, d time.Duration) overlaySource {
+	return overlaySource{
+		name: name,
+		fetch: func(ctx context.Context) (map[string]any, error) {
+			select {
+			case <-time.After(d):
+				return map[string]any{"source": name}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}
+}
+
+func TestFetchOverlaysConcurrently(t *testing.T) {
+	sources := []overlaySource{
+		sleepySource("slow", 100*time.Millisecond),
+		sleepySource("fast", 10*time.Millisecond),
+		sleepySource("medium", 50*time.Millisecond),
+	}
+
+	start := time.Now()
+	results := fetchOverlays(sources, time.Second)
+	if elapsed := time.Since(start); elapsed >= 160*time.Millisecond {
+		t.Errorf("Expected concurrent fetch to take about the slowest source, took %s", elapsed)
+	}
+
+	// Results keep the declaration order regardless of completion order
+	for i, src := range sources {
+		if results[i].err != nil {
+			t.Fatalf("Unexpected error from %s: %v", src.name, results[i].err)
+		}
+		if got := results[i].settings["source"]; got != src.name {
+			t.Errorf("Result %d: expected %s, got %v", i, src.name, got)
+		}
+	}
+}
+
+func TestFetchOverlaysDeadline(t *testing.T) {
+	stuck := overlaySource{
+		name: "stuck",
+		fetch: func(context.Context) (map[string]any, error) {
+			time.Sleep(time.Second)
+			return nil, nil
+		},
+	}
+	sources := []overlaySource{sleepySource("fast", 0), stuck}
+
+	start := time.Now()
+	results := fetchOverlays(sources, 50*time.Millisecond)
+	if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+		t.Errorf("Expected the deadline to bound loading, took %s", elapsed)
+	}
+	if results[0].err != nil {
+		t.Errorf("Expected fast source to succeed, got %v", results[0].err)
+	}
+	if !errors.Is(results[1].err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error for stuck source, got %v", results[1].err)
+	}
+}
+
+func TestFetchOverlaysCancelsTimedOutSource(t *testing.T) {
+	exited := make(chan struct{})
+	blocked := overlaySource{
+		name: "blocked",
+		fetch: func(ctx context.Context) (map[string]any, error) {
+			defer close(exited)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	fetchOverlays([]overlaySource{blocked}, 50*time.Millisecond)
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the timed out fetch to see its context canceled and return")
+	}
+}
+
+func TestReadExtensionsDirStopsWhenCanceled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cache.yaml"), []byte("ttl: 60\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := readExtensionsDir(ctx, dir); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
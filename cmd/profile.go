@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// Load phases reported by --profile-load, in load order. Viper resolves
+// environment variables lazily, so their lookup is part of the decode phase.
+const (
+	phaseFileRead     = "file read"
+	phaseOverlayFetch = "overlay fetch"
+	phaseOverlayMerge = "overlay merge"
+	phaseDecode       = "decode"
+	phaseSecrets      = "secret resolution"
+	phaseValidation   = "validation"
+	phaseCacheWrite   = "cache write"
+)
+
+var (
+	// profileLoad selects the --profile-load report format: table or json
+	profileLoad string
+
+	// loadProfile collects the phases of the first configuration load
+	loadProfile struct {
+		start   time.Time
+		phases  []loadPhase
+		printed bool
+	}
+)
+
+// loadPhase is the time spent in one phase; sources break the overlay fetch
+// down per source
+type loadPhase struct {
+	Name     string        `json:"phase"`
+	Duration time.Duration `json:"-"`
+	Millis   float64       `json:"duration_ms"`
+	Sources  []loadPhase   `json:"sources,omitempty"`
+	Cached   bool          `json:"cached,omitempty"`
+}
+
+// profiling reports whether load phases are being recorded
+func profiling() bool {
+	return profileLoad != "" && !loadProfile.printed
+}
+
+// recordPhase adds the time since start to the named phase; phases that run in
+// several steps, such as secret resolution, accumulate
+func recordPhase(name string, start time.Time) {
+	if !profiling() {
+		return
+	}
+	d := time.Since(start)
+	for i := range loadProfile.phases {
+		if loadProfile.phases[i].Name == name {
+			loadProfile.phases[i].Duration += d
+			return
+		}
+	}
+	loadProfile.phases = append(loadProfile.phases, loadPhase{Name: name, Duration: d})
+}
+
+// recordSource adds a per-source entry to the overlay fetch phase
+func recordSource(name string, d time.Duration, cached bool) {
+	if !profiling() {
+		return
+	}
+	for i := range loadProfile.phases {
+		if loadProfile.phases[i].Name == phaseOverlayFetch {
+			loadProfile.phases[i].Sources = append(loadProfile.phases[i].Sources, loadPhase{Name: name, Duration: d, Cached: cached})
+			return
+		}
+	}
+}
+
+// printLoadProfile writes the report of the first load to stderr. Later loads,
+// such as reloads in serve mode, are not profiled.
+func printLoadProfile() {
+	if !profiling() {
+		return
+	}
+	loadProfile.printed = true
+	total := time.Since(loadProfile.start)
+	if err := writeLoadProfile(os.Stderr, profileLoad, loadProfile.phases, total); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing load profile: %v\n", err)
+	}
+}
+
+func writeLoadProfile(w io.Writer, format string, phases []loadPhase, total time.Duration) error {
+	// Time outside the named phases, e.g. opening the audit log
+	other := total
+	for _, p := range phases {
+		other -= p.Duration
+	}
+	if other < 0 {
+		other = 0
+	}
+
+	if format == "json" {
+		for i := range phases {
+			phases[i].Millis = millis(phases[i].Duration)
+			for j := range phases[i].Sources {
+				phases[i].Sources[j].Millis = millis(phases[i].Sources[j].Duration)
+			}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Phases  []loadPhase `json:"phases"`
+			OtherMS float64     `json:"other_ms"`
+			TotalMS float64     `json:"total_ms"`
+		}{phases, millis(other), millis(total)})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tDURATION\tSHARE")
+	for _, p := range phases {
+		fmt.Fprintf(tw, "%s\t%s\t%.1f%%\n", p.Name, p.Duration.Round(time.Microsecond), share(p.Duration, total))
+		for _, s := range p.Sources {
+			name := "  " + s.Name
+			if s.Cached {
+				name += " (cached)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t\n", name, s.Duration.Round(time.Microsecond))
+		}
+	}
+	fmt.Fprintf(tw, "other\t%s\t%.1f%%\n", other.Round(time.Microsecond), share(other, total))
+	fmt.Fprintf(tw, "total\t%s\t\n", total.Round(time.Microsecond))
+	return tw.Flush()
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func share(d, total time.Duration) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return 100 * float64(d) / float64(total)
+}
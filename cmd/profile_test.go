@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleProfile() []loadPhase {
+	return []loadPhase{
+		{Name: phaseFileRead, Duration: 2 * time.Millisecond},
+		{Name: phaseOverlayFetch, Duration: 5 * time.Millisecond, Sources: []loadPhase{
+			{Name: "registry", Duration: 5 * time.Millisecond},
+			{Name: "extensions.d", Cached: true},
+		}},
+		{Name: phaseValidation, Duration: 1 * time.Millisecond},
+	}
+}
+
+func TestWriteLoadProfileTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeLoadProfile(&buf, "table", sampleProfile(), 10*time.Millisecond); err != nil {
+		t.Fatalf("writeLoadProfile failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"overlay fetch", "50.0%", "extensions.d (cached)", "other", "20.0%", "total"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in table:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteLoadProfileJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeLoadProfile(&buf, "json", sampleProfile(), 10*time.Millisecond); err != nil {
+		t.Fatalf("writeLoadProfile failed: %v", err)
+	}
+	var report struct {
+		Phases  []loadPhase `json:"phases"`
+		OtherMS float64     `json:"other_ms"`
+		TotalMS float64     `json:"total_ms"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+	}
+	if report.TotalMS != 10 || report.OtherMS != 2 || report.Phases[1].Sources[0].Millis != 5 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// registryKeys holds the viper keys provided by the Windows registry
+	registryKeys = map[string]bool{}
+
+	// registryLoadErr holds a registry warning that --strict-warnings promoted to
+	// an error, which fails the next configuration load
+	registryLoadErr error
+)
+
+// registryOverlay reads settings from the Windows registry in builds with the
+// registry tag. Viper keeps them in
+// the config layer, so env vars and flags still take precedence.
+func registryOverlay() overlaySource {
+	return overlaySource{
+		name:  "registry",
+		fetch: readRegistrySettings,
+		apply: applyRegistry,
+	}
+}
+
+// applyRegistry merges registry settings on top of the config file. A registry
+// that cannot be read or merged is skipped with a warning.
+func applyRegistry(settings map[string]any, err error) {
+	registryKeys = map[string]bool{}
+	registryLoadErr = nil
+	if err != nil {
+		registryLoadErr = reportWarnings(os.Stderr, []string{fmt.Sprintf("reading registry %s: %v; ignoring it", registrySource, err)})
+		return
+	}
+	if len(settings) == 0 {
+		return
+	}
+	if err := v.MergeConfigMap(settings); err != nil {
+		registryLoadErr = reportWarnings(os.Stderr, []string{fmt.Sprintf("merging registry %s: %v; ignoring it", registrySource, err)})
+		return
+	}
+	collectKeys("", settings, registryKeys)
+	fmt.Fprintf(os.Stderr, "Using registry key: %s\n", registrySource)
+}
+
+// registrySettings nests registry values, keyed by their path below the
+// registry key such as `Server\Port`, into settings: subkeys become sections and
+// value names keys, lowercased like viper keys. A subkey wins over a value of
+// the same name.
+func registrySettings(values map[string]any) map[string]any {
+	settings := map[string]any{}
+	for path, value := range values {
+		parts := strings.Split(strings.ToLower(path), `\`)
+		m := settings
+		for _, part := range parts[:len(parts)-1] {
+			nested, ok := m[part].(map[string]any)
+			if !ok {
+				nested = map[string]any{}
+				m[part] = nested
+			}
+			m = nested
+		}
+		if _, isSection := m[parts[len(parts)-1]].(map[string]any); !isSection {
+			m[parts[len(parts)-1]] = value
+		}
+	}
+	return settings
+}
+
+// collectKeys records the dotted leaf keys of a nested settings map
+func collectKeys(prefix string, settings map[string]any, keys map[string]bool) {
+	for name, value := range settings {
+		key := strings.ToLower(name)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			collectKeys(key, nested, keys)
+			continue
+		}
+		keys[key] = true
+	}
+}
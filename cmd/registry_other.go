@@ -0,0 +1,13 @@
+//go:build !windows || !registry
+
+package cmd
+
+import "context"
+
+// registrySource names the registry location in user-facing messages
+const registrySource = ""
+
+// readRegistrySettings is a no-op outside Windows builds with the registry tag
+func readRegistrySettings(context.Context) (map[string]any, error) {
+	return nil, nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRegistrySettings(t *testing.T) {
+	values := map[string]any{
+		`Server\Port`:                  int64(8080),
+		`Server\Shutdown\grace_period`: "30s",
+		`APP\Name`:                     "demo",
+		`Logging`:                      "shadowed by the subkey",
+		`Logging\Level`:                "debug",
+		`Database\Hosts`:               []string{"a", "b"},
+	}
+	want := map[string]any{
+		"server": map[string]any{
+			"port":     int64(8080),
+			"shutdown": map[string]any{"grace_period": "30s"},
+		},
+		"app":      map[string]any{"name": "demo"},
+		"logging":  map[string]any{"level": "debug"},
+		"database": map[string]any{"hosts": []string{"a", "b"}},
+	}
+	for i := 0; i < 10; i++ {
+		if got := registrySettings(values); !reflect.DeepEqual(got, want) {
+			t.Fatalf("registrySettings() =\n%#v\nwant\n%#v", got, want)
+		}
+	}
+}
+
+func TestCollectKeys(t *testing.T) {
+	settings := map[string]any{
+		"Server": map[string]any{
+			"port":     8080,
+			"Shutdown": map[string]any{"grace_period": "30s"},
+		},
+		"app": map[string]any{"name": "demo"},
+	}
+	keys := map[string]bool{}
+	collectKeys("", settings, keys)
+	want := map[string]bool{"server.port": true, "server.shutdown.grace_period": true, "app.name": true}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("collectKeys() = %v, want %v", keys, want)
+	}
+}
+
+func TestApplyRegistryStrictWarnings(t *testing.T) {
+	defer func() { registryKeys, registryLoadErr = map[string]bool{}, nil }()
+	registryKeys = map[string]bool{"server.port": true}
+
+	t.Setenv(strictWarningsEnv, "false")
+	applyRegistry(nil, errors.New("access denied"))
+	if registryLoadErr != nil || len(registryKeys) != 0 {
+		t.Errorf("Expected an unreadable registry to be skipped with a warning, got %v, %v", registryLoadErr, registryKeys)
+	}
+
+	t.Setenv(strictWarningsEnv, "true")
+	applyRegistry(nil, errors.New("access denied"))
+	var warningsErr *warningsError
+	if !errors.As(registryLoadErr, &warningsErr) {
+		t.Errorf("Expected a warningsError under strict warnings, got %v", registryLoadErr)
+	}
+	applyRegistry(nil, nil)
+	if registryLoadErr != nil {
+		t.Errorf("Expected a later clean read to clear the error, got %v", registryLoadErr)
+	}
+}
@@ -0,0 +1,107 @@
+//go:build windows && registry
+
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// registryPath is the key below HKEY_LOCAL_MACHINE holding configuration managed
+// through Group Policy. Subkeys map to sections, value names to keys, e.g.
+// HKLM\Software\MyApp\Server\Port (REG_DWORD) sets server.port.
+const registryPath = `Software\MyApp`
+
+// registrySource names the registry location in user-facing messages
+const registrySource = `HKLM\` + registryPath
+
+// readRegistrySettings returns the settings stored under registryPath as a
+// nested map, or nil when the key does not exist. It stops with ctx's error
+// once ctx is done.
+func readRegistrySettings(ctx context.Context) (map[string]any, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, registryPath, registry.READ)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+	values := map[string]any{}
+	if err := readRegistryKey(ctx, key, "", values); err != nil {
+		return nil, err
+	}
+	return registrySettings(values), nil
+}
+
+// readRegistryKey adds the values of key and its subkeys to values, keyed by
+// their path below registryPath
+func readRegistryKey(ctx context.Context, key registry.Key, path string, values map[string]any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	names, err := key.ReadValueNames(0)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		value, err := readRegistryValue(key, name)
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			values[joinRegistryPath(path, name)] = value
+		}
+	}
+
+	subkeys, err := key.ReadSubKeyNames(0)
+	if err != nil {
+		return err
+	}
+	for _, name := range subkeys {
+		sub, err := registry.OpenKey(key, name, registry.READ)
+		if err != nil {
+			return err
+		}
+		err = readRegistryKey(ctx, sub, joinRegistryPath(path, name), values)
+		sub.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinRegistryPath appends a subkey or value name to a registry path
+func joinRegistryPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + `\` + name
+}
+
+// readRegistryValue converts string and integer values; other types are ignored
+func readRegistryValue(key registry.Key, name string) (any, error) {
+	_, valType, err := key.GetValue(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch valType {
+	case registry.SZ, registry.EXPAND_SZ:
+		s, _, err := key.GetStringValue(name)
+		if err == nil && valType == registry.EXPAND_SZ {
+			s, err = registry.ExpandString(s)
+		}
+		return s, err
+	case registry.DWORD, registry.QWORD:
+		n, _, err := key.GetIntegerValue(name)
+		return int64(n), err
+	case registry.MULTI_SZ:
+		s, _, err := key.GetStringsValue(name)
+		return s, err
+	default:
+		return nil, nil
+	}
+}
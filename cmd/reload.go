@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/example/cobra-viper-demo/audit"
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/example/cobra-viper-demo/metrics"
+	"github.com/go-playground/validator/v10"
+)
+
+// metricsRecorder collects configuration metrics; nil when metrics are disabled
+var metricsRecorder *metrics.Recorder
+
+// liveConfig holds the configuration in effect in serve mode and swaps in new
+// candidates only once they pass validation
+type liveConfig struct {
+	mu            sync.RWMutex
+	cfg           *config.Config
+	hash          string
+	sources       sourceSummary
+	loadedAt      time.Time
+	lastReloadAt  time.Time
+	lastReloadErr error
+	draining      bool
+}
+
+func newLiveConfig(cfg *config.Config) *liveConfig {
+	return &liveConfig{cfg: cfg, hash: cfg.Hash(), sources: summarizeSources(), loadedAt: time.Now()}
+}
+
+// Get returns the configuration currently in effect. Callers must not modify it;
+// use Clone to derive a candidate.
+func (l *liveConfig) Get() *config.Config {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.cfg
+}
+
+// drain marks the process as shutting down, which /healthz reports
+func (l *liveConfig) drain() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.draining = true
+}
+
+// reload re-reads the merged viper settings and applies them if they are valid.
+// A rejected candidate leaves the current configuration in place.
+func (l *liveConfig) reload(trigger string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastReloadAt = time.Now()
+	candidate, err := unmarshalAndValidate(l.cfg)
+	event := audit.Event{Type: audit.EventReload, Source: trigger}
+	if err != nil {
+		l.lastReloadErr = err
+		metricsRecorder.ObserveReload(false, "", invalidKeys(err))
+		event.Outcome = audit.OutcomeRejected
+		event.Reasons = errorReasons(err)
+		auditLog.Record(event)
+		if invalidKeys(err) == nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		fmt.Fprintln(os.Stderr, "Configuration reload rejected, keeping previous configuration")
+		return err
+	}
+
+	var keys []string
+	for _, c := range l.cfg.Diff(candidate) {
+		keys = append(keys, c.Key)
+	}
+	hash := candidate.Hash()
+	l.cfg = candidate
+	l.hash = hash
+	l.sources = summarizeSources()
+	l.loadedAt = l.lastReloadAt
+	l.lastReloadErr = nil
+
+	metricsRecorder.ObserveReload(true, hash, nil)
+	event.Outcome = audit.OutcomeAccepted
+	event.Hash = hash
+	event.Keys = keys
+	auditLog.Record(event)
+	fmt.Fprintf(os.Stderr, "Configuration reloaded from %s (%d key(s) changed)\n", trigger, len(keys))
+	return nil
+}
+
+// reloadMu serializes reloads, which the file watcher and remote sources can
+// trigger concurrently
+var reloadMu sync.Mutex
+
+// reloadAll re-reads the config file and the overlay sources, then reloads. Every
+// reload trigger goes through it, so viper is only re-read under reloadMu.
+func (l *liveConfig) reloadAll(trigger string) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if v.ConfigFileUsed() != "" {
+		if err := v.ReadInConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading config file: %v\n", err)
+			return
+		}
+	}
+	mergeOverlays()
+	l.reload(trigger)
+}
+
+// invalidKeys returns the config keys that failed validation in err
+func invalidKeys(err error) []string {
+	var rulesErr *config.RulesError
+	if errors.As(err, &rulesErr) {
+		keys := make([]string, len(rulesErr.Violations))
+		for i, violation := range rulesErr.Violations {
+			keys[i] = violation.Key
+		}
+		return keys
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+	var extErr *config.ExtensionError
+	isExtension := errors.As(err, &extErr)
+	keys := make([]string, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		if isExtension {
+			keys = append(keys, extErr.Key(fe.StructNamespace()))
+		} else {
+			keys = append(keys, config.KeyForNamespace(fe.StructNamespace()))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/example/cobra-viper-demo/metrics"
+	"github.com/spf13/viper"
+)
+
+// useViper replaces the global viper instance with settings for the duration
+// of a test
+func useViper(t *testing.T, settings map[string]any) {
+	t.Helper()
+	saved := v
+	t.Cleanup(func() { v = saved })
+	v = viper.NewWithOptions(viper.WithCodecRegistry(newCodecRegistry()))
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+}
+
+func TestReloadKeepsConfigOnValidationFailure(t *testing.T) {
+	useViper(t, map[string]any{"app.name": "demo", "server.port": 8080})
+	savedRecorder := metricsRecorder
+	defer func() { metricsRecorder = savedRecorder }()
+	metricsRecorder = metrics.New()
+
+	cfg, err := unmarshalAndValidate(nil)
+	if err != nil {
+		t.Fatalf("Initial load failed: %v", err)
+	}
+	live := newLiveConfig(cfg)
+
+	v.Set("server.port", 80)
+	if err := live.reload("test"); err == nil {
+		t.Fatal("Expected the reload of an invalid port to be rejected")
+	}
+	if got := live.Get(); got != cfg || got.Server.Port != 8080 {
+		t.Errorf("Expected the previous config to stay live, got port %d", got.Server.Port)
+	}
+
+	v.Set("server.port", 8081)
+	if err := live.reload("test"); err != nil {
+		t.Fatalf("Expected a valid reload to be accepted, got %v", err)
+	}
+	if got := live.Get().Server.Port; got != 8081 {
+		t.Errorf("Expected the reloaded port 8081, got %d", got)
+	}
+
+	rec := httptest.NewRecorder()
+	metricsRecorder.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{
+		"myapp_config_reload_attempts_total 2\n",
+		"myapp_config_reload_failures_total 1\n",
+		`myapp_config_validation_errors_total{field="server.port"} 1` + "\n",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("Expected %q in metrics", want)
+		}
+	}
+}
@@ -1,20 +1,40 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/example/cobra-viper-demo/codec"
 	"github.com/example/cobra-viper-demo/config"
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// envPrefix is the prefix of every environment variable read by the application
+const envPrefix = "MYAPP"
+
 var (
 	cfgFile string
 	v       *viper.Viper
+
+	// displayOutput is the --output format of the root command
+	displayOutput string
+
+	// displaySecrets controls whether the root command shows secret values
+	displaySecrets secretsOptions
+
+	// flagBindings maps viper keys to the name of the flag bound to them
+	flagBindings = map[string]string{}
+
+	// flagErrors collects flag definition and binding problems found while
+	// registering flags, so that all of them are reported at once
+	flagErrors []error
 )
 
 var rootCmd = &cobra.Command{
@@ -24,48 +44,134 @@ var rootCmd = &cobra.Command{
 1. Command-line flags (highest priority)
 2. Environment variables (medium priority)
 3. Configuration file (lowest priority)`,
-	Run: func(cmd *cobra.Command, args []string) {
-		displayConfiguration()
-	},
+	// Execute reports errors itself
+	SilenceErrors: true,
 }
 
 func Execute() {
+	if err := checkFlags(rootCmd); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid flag registration:\n%v\n", err)
+		os.Exit(1)
+	}
+	if err := localizeHelp(rootCmd, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// bindFlag binds an already defined flag to a viper key and records the binding
+func bindFlag(cmd *cobra.Command, viperKey, flagName string) {
+	if err := v.BindPFlag(viperKey, cmd.PersistentFlags().Lookup(flagName)); err != nil {
+		flagErrors = append(flagErrors, fmt.Errorf("failed to bind flag --%s to %s: %w", flagName, viperKey, err))
+		return
+	}
+	flagBindings[viperKey] = flagName
+}
+
+// envVarName returns the environment variable that overrides a viper key
+func envVarName(viperKey string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(viperKey, ".", "_"))
+}
+
+// defaultValue returns the default of a viper key as declared by its bound flag
+func defaultValue(viperKey string) string {
+	flagName, ok := flagBindings[viperKey]
+	if !ok {
+		return ""
+	}
+	return rootCmd.PersistentFlags().Lookup(flagName).DefValue
+}
+
 // bindStringFlag defines a string flag and binds it to viper in one call
 func bindStringFlag(cmd *cobra.Command, viperKey, flagName, shorthand, defaultVal, usage string) {
-	cmd.Flags().StringP(flagName, shorthand, defaultVal, usage)
-	if err := v.BindPFlag(viperKey, cmd.Flags().Lookup(flagName)); err != nil {
-		panic(fmt.Sprintf("failed to bind flag %s to %s: %v", flagName, viperKey, err))
+	if !canDefineFlag(cmd, viperKey, flagName, shorthand) {
+		return
 	}
+	cmd.PersistentFlags().StringP(flagName, shorthand, defaultVal, usage)
+	bindFlag(cmd, viperKey, flagName)
 }
 
 // bindIntFlag defines an int flag and binds it to viper in one call
 func bindIntFlag(cmd *cobra.Command, viperKey, flagName, shorthand string, defaultVal int, usage string) {
-	cmd.Flags().IntP(flagName, shorthand, defaultVal, usage)
-	if err := v.BindPFlag(viperKey, cmd.Flags().Lookup(flagName)); err != nil {
-		panic(fmt.Sprintf("failed to bind flag %s to %s: %v", flagName, viperKey, err))
+	if !canDefineFlag(cmd, viperKey, flagName, shorthand) {
+		return
 	}
+	cmd.PersistentFlags().IntP(flagName, shorthand, defaultVal, usage)
+	bindFlag(cmd, viperKey, flagName)
 }
 
 // bindBoolFlag defines a bool flag and binds it to viper in one call
 func bindBoolFlag(cmd *cobra.Command, viperKey, flagName, shorthand string, defaultVal bool, usage string) {
-	cmd.Flags().BoolP(flagName, shorthand, defaultVal, usage)
-	if err := v.BindPFlag(viperKey, cmd.Flags().Lookup(flagName)); err != nil {
-		panic(fmt.Sprintf("failed to bind flag %s to %s: %v", flagName, viperKey, err))
+	if !canDefineFlag(cmd, viperKey, flagName, shorthand) {
+		return
+	}
+	cmd.PersistentFlags().BoolP(flagName, shorthand, defaultVal, usage)
+	bindFlag(cmd, viperKey, flagName)
+}
+
+// bindDurationFlag defines a duration flag and binds it to viper in one call
+func bindDurationFlag(cmd *cobra.Command, viperKey, flagName, shorthand string, defaultVal time.Duration, usage string) {
+	if !canDefineFlag(cmd, viperKey, flagName, shorthand) {
+		return
+	}
+	cmd.PersistentFlags().DurationP(flagName, shorthand, defaultVal, usage)
+	bindFlag(cmd, viperKey, flagName)
+}
+
+// newCodecRegistry registers every supported format, adding .properties and
+// .ini to viper's built-ins, behind a codec that transparently decrypts
+// age- and SOPS-encrypted files
+func newCodecRegistry() viper.CodecRegistry {
+	registry := viper.NewCodecRegistry()
+	formats := map[string]codec.Codec{
+		"yaml":       codec.YAML{},
+		"yml":        codec.YAML{},
+		"json":       codec.JSON{},
+		"toml":       codec.TOML{},
+		"properties": codec.Properties{},
+		"props":      codec.Properties{},
+		"prop":       codec.Properties{},
+		"ini":        codec.INI{},
+	}
+	for format, c := range formats {
+		registry.RegisterCodec(format, codec.Decrypting{Codec: c, Format: format})
 	}
+	return registry
 }
 
 func init() {
-	v = viper.New()
+	v = viper.NewWithOptions(viper.WithCodecRegistry(newCodecRegistry()))
 	cobra.OnInitialize(initConfig)
 
+	// Set here rather than in the literal: loading reads flag state through
+	// rootCmd, which would make its initialization refer to itself
+	rootCmd.Run = func(cmd *cobra.Command, args []string) {
+		displayConfiguration(cmd.OutOrStdout())
+	}
+
 	// Config file flag (not bound to viper, handled separately)
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file, or git+<repo URL>//<path>?ref=<ref> for a file in a git repository (default is ./config.yaml)")
+	rootCmd.PersistentFlags().DurationVar(&overlayTimeout, "sources-timeout", 30*time.Second, "overall deadline for loading the registry, extensions.d, and other overlay sources, and for fetching a git config repository")
+	rootCmd.PersistentFlags().DurationVar(&gitPollInterval, "git-poll-interval", time.Minute, "how often serve checks a git config repository for new commits")
+	rootCmd.PersistentFlags().BoolVar(&noConfigCache, "no-config-cache", false, "bypass the configuration cache enabled by MYAPP_CONFIG_CACHE")
+	rootCmd.PersistentFlags().StringVar(&profileLoad, "profile-load", "", "print how long each configuration load phase took, as a table or json")
+	rootCmd.PersistentFlags().Lookup("profile-load").NoOptDefVal = "table"
+	rootCmd.PersistentFlags().BoolVar(&debugOutput, "debug", false, "print diagnostic output such as source load timings")
+	rootCmd.PersistentFlags().StringVar(&rulesFile, "rules", "", "rules file with site-specific constraints (default is rules.yaml next to the config file)")
+	rootCmd.PersistentFlags().StringVar(&kubeConfigMap, "k8s-configmap", "", "ConfigMap to read through the Kubernetes API, as name or namespace/name")
+	rootCmd.PersistentFlags().StringVar(&kubeSecret, "k8s-secret", "", "Secret to read through the Kubernetes API, as name or namespace/name")
+	rootCmd.PersistentFlags().BoolVar(&strictWarnings, "strict-warnings", false, "treat warnings, such as deprecations and rules-file warnings, as errors (default from MYAPP_STRICT_WARNINGS)")
+	rootCmd.PersistentFlags().StringVar(&helpLang, "lang", "", "language of help text (default from MYAPP_LANG, LC_ALL, LC_MESSAGES, or LANG)")
+	rootCmd.PersistentFlags().StringVar(&extensionsDir, "extensions-dir", "", "directory of extension config fragments (default is extensions.d next to the config file)")
+
+	// Display flags, local to the root command
+	rootCmd.Flags().StringVarP(&displayOutput, "output", "o", outputJSON, outputUsage)
+	rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(outputFormats, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace))
+	displaySecrets.addFlags(rootCmd, rootCmd.Flags())
 
 	// Application flags
 	bindStringFlag(rootCmd, "app.name", "app-name", "n", "", "Application name")
@@ -76,163 +182,388 @@ func init() {
 	bindStringFlag(rootCmd, "server.host", "server-host", "", "", "Server host")
 	bindIntFlag(rootCmd, "server.port", "server-port", "p", 0, "Server port")
 	bindIntFlag(rootCmd, "server.timeout", "server-timeout", "t", 0, "Server timeout in seconds")
+	bindDurationFlag(rootCmd, "server.shutdown.grace_period", "server-shutdown-grace-period", "", 30*time.Second, "Maximum time for a graceful shutdown")
+	bindDurationFlag(rootCmd, "server.shutdown.drain_timeout", "server-shutdown-drain-timeout", "", 10*time.Second, "Time /healthz reports draining on shutdown before servers stop accepting connections")
+	bindStringFlag(rootCmd, "server.tls.cert", "tls-cert", "", "", "TLS certificate file for serve mode")
+	bindStringFlag(rootCmd, "server.tls.key", "tls-key", "", "", "TLS private key file for serve mode")
 
 	// Database flags
 	bindStringFlag(rootCmd, "database.host", "db-host", "", "", "Database host")
 	bindIntFlag(rootCmd, "database.port", "db-port", "", 0, "Database port")
 	bindStringFlag(rootCmd, "database.username", "db-username", "u", "", "Database username")
 	bindStringFlag(rootCmd, "database.password", "db-password", "", "", "Database password")
+	bindStringFlag(rootCmd, "database.password_file", "db-password-file", "", "", "File containing the database password")
 	bindStringFlag(rootCmd, "database.name", "db-name", "d", "", "Database name")
 
 	// Logging flags
 	bindStringFlag(rootCmd, "logging.level", "log-level", "l", "", "Logging level")
 	bindStringFlag(rootCmd, "logging.format", "log-format", "f", "", "Logging format")
+
+	// Audit flags
+	bindBoolFlag(rootCmd, "audit.enabled", "audit-enabled", "", false, "Enable the configuration audit log")
+	bindStringFlag(rootCmd, "audit.sink", "audit-sink", "", "", "Audit log sink (stderr or file)")
+	bindStringFlag(rootCmd, "audit.path", "audit-path", "", "", "Audit log file path when the sink is file")
+
+	// Metrics flags
+	bindBoolFlag(rootCmd, "metrics.enabled", "metrics-enabled", "", false, "Expose Prometheus metrics in serve mode")
+	bindStringFlag(rootCmd, "metrics.listen", "metrics-listen", "", ":9090", "Metrics listen address")
+
+	// Admin flags
+	bindBoolFlag(rootCmd, "admin.enabled", "admin-enabled", "", false, "Expose the admin endpoint in serve mode")
+	bindStringFlag(rootCmd, "admin.listen", "admin-listen", "", "127.0.0.1:9091", "Admin listen address")
+
+	// Legacy flag spellings, kept working for existing scripts
+	aliasFlag(rootCmd, "db_host", "db-host")
+	aliasFlag(rootCmd, "db_port", "db-port")
+	aliasFlag(rootCmd, "db_username", "db-username")
+	aliasFlag(rootCmd, "db_name", "db-name")
+
+	// Flag relationships, enforced at parse time. Marking panics on missing
+	// flags, so skip it when registration problems are pending for Execute to report.
+	if len(flagErrors) == 0 {
+		rootCmd.MarkFlagsMutuallyExclusive("db-password", "db-password-file")
+		rootCmd.MarkFlagsRequiredTogether("tls-cert", "tls-key")
+	}
+
+	// Shell completion for flag values
+	rootCmd.MarkPersistentFlagFilename("config", "yaml", "yml", "json", "toml", "properties", "props", "prop", "ini")
+	rootCmd.MarkPersistentFlagDirname("extensions-dir")
+	rootCmd.MarkPersistentFlagFilename("rules", "yaml", "yml")
+	registerValueCompletions()
 }
 
 func initConfig() {
+	if isCompletionRequest(os.Args[1:]) {
+		return
+	}
+	applyFlagAliases(rootCmd)
+	checkEarlyWarnings()
+	if profileLoad != "" && profileLoad != "table" && profileLoad != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid --profile-load format %q (expected table or json)\n", profileLoad)
+		os.Exit(1)
+	}
+	loadProfile.start = time.Now()
+
 	// Check for config file in order of precedence:
 	// 1. --config flag (highest priority)
 	// 2. MYAPP_CONFIG environment variable
 	// 3. Default search paths
 	if cfgFile != "" {
 		// Use config file from the flag
-		v.SetConfigFile(cfgFile)
+		v.SetConfigFile(configLocation(cfgFile))
 	} else if envConfigFile := os.Getenv("MYAPP_CONFIG"); envConfigFile != "" {
 		// Use config file from environment variable
-		v.SetConfigFile(envConfigFile)
+		v.SetConfigFile(configLocation(envConfigFile))
 	} else {
-		// Search for config in the current directory with name "config" (without extension)
+		// Search the current directory for "config" with any supported extension
+		// (config.yaml, config.properties, config.ini, ...); the extension picks the format
 		v.AddConfigPath(".")
 		v.SetConfigName("config")
-		v.SetConfigType("yaml")
 	}
 
 	// Enable environment variable support
-	v.SetEnvPrefix("MYAPP") // will be uppercased automatically
+	v.SetEnvPrefix(envPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
 	// Read the configuration file
-	if err := v.ReadInConfig(); err == nil {
-		fmt.Printf("Using config file: %s\n\n", v.ConfigFileUsed())
+	start := time.Now()
+	err := v.ReadInConfig()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		// The search only considers names with an extension; an extensionless
+		// ./config is read as YAML, as it always has been
+		if info, statErr := os.Stat("config"); statErr == nil && info.Mode().IsRegular() {
+			v.SetConfigFile("config")
+			v.SetConfigType("yaml")
+			err = v.ReadInConfig()
+		}
+	}
+	recordPhase(phaseFileRead, start)
+	debugf("read config file in %s", time.Since(start).Round(time.Microsecond))
+	if err == nil {
+		fmt.Fprintf(os.Stderr, "Using config file: %s\n\n", v.ConfigFileUsed())
 	} else {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			fmt.Println("No config file found, using flags and environment variables only")
+			fmt.Fprintln(os.Stderr, "No config file found, using flags and environment variables only")
 		} else {
 			fmt.Fprintf(os.Stderr, "Error reading config file: %v\n\n", err)
 		}
 	}
+
+	mergeOverlays()
 }
 
-// loadAndValidateConfig loads configuration from viper and validates it
+// configLocation returns the local path of the config file, checking out the
+// repository first when the location is a git+ URL
+func configLocation(location string) string {
+	if _, ok, _ := parseGitSource(location); !ok {
+		return location
+	}
+	start := time.Now()
+	path, err := resolveGitConfig(location)
+	recordPhase(phaseFileRead, start)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return path
+}
+
+// loadAndValidateConfig loads configuration from viper, validates it, and records
+// the outcome in the audit log
 func loadAndValidateConfig() (*config.Config, error) {
-	// Unmarshal the configuration into the struct
+	if err := openAuditLog(); err != nil {
+		return nil, err
+	}
+	cfg, err := unmarshalAndValidate(nil)
+	auditConfigLoad(cfg, err)
+	printLoadProfile()
+	return cfg, err
+}
+
+// unmarshalAndValidate decodes the merged viper settings and validates them. With
+// prev, the configuration in effect, only what changed since is revalidated.
+func unmarshalAndValidate(prev *config.Config) (*config.Config, error) {
+	if extensionsLoadErr != nil {
+		return nil, fmt.Errorf("error loading extensions: %w", extensionsLoadErr)
+	}
+	if registryLoadErr != nil {
+		return nil, fmt.Errorf("error loading registry: %w", registryLoadErr)
+	}
+	if err := kubeLoadErr(); err != nil {
+		return nil, fmt.Errorf("error loading Kubernetes objects: %w", err)
+	}
+
+	// Unmarshal the configuration into the struct, unless the config cache holds
+	// the result for unchanged inputs
 	var cfg config.Config
-	if err := v.UnmarshalExact(&cfg); err != nil {
+	start := time.Now()
+	if cached := cachedConfig(); cached != nil {
+		debugf("using cached configuration")
+		cfg = *cached
+	} else if err := v.UnmarshalExact(&cfg); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
+	decoded := cfg.Clone()
+	recordPhase(phaseDecode, start)
+
+	// Decrypt inline encrypted values
+	start = time.Now()
+	err := decryptInlineValues(&cfg)
+	recordPhase(phaseSecrets, start)
+	if err != nil {
+		return nil, err
+	}
 
-	// Validate the configuration
-	if err := validateConfig(&cfg); err != nil {
+	// Validate the configuration and evaluate site-specific rules
+	start = time.Now()
+	err = validateChanges(os.Stderr, prev, &cfg)
+	if err == nil {
+		err = checkRulesFile(os.Stderr, &cfg, v.ConfigFileUsed())
+	}
+	if err == nil {
+		err = reportWarnings(os.Stderr, deprecationWarnings(config.Fields(), func(key string) bool {
+			return keySource(key) != sourceDefault
+		}))
+	}
+	recordPhase(phaseValidation, start)
+	if err != nil {
 		return nil, err
 	}
 
+	// Resolve secrets referenced by file
+	start = time.Now()
+	err = resolvePasswordFile(&cfg)
+	recordPhase(phaseSecrets, start)
+	if err != nil {
+		return nil, err
+	}
+
+	start = time.Now()
+	saveConfigCache(decoded)
+	recordPhase(phaseCacheWrite, start)
 	return &cfg, nil
 }
 
-// validateConfig validates the configuration struct and returns detailed error messages
-func validateConfig(cfg *config.Config) error {
-	validate := validator.New()
-	if err := validate.Struct(cfg); err != nil {
+// mustLoadConfig loads and validates the configuration, exiting on failure.
+// Validation and rules-file failures are already reported in detail.
+func mustLoadConfig() *config.Config {
+	cfg, err := loadAndValidateConfig()
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		var rulesErr *config.RulesError
+		if !errors.As(err, &validationErrors) && !errors.As(err, &rulesErr) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// validateConfig validates the configuration struct and any registered extensions,
+// reporting detailed error messages to w
+func validateConfig(w io.Writer, cfg *config.Config) error {
+	return validateChanges(w, nil, cfg)
+}
+
+// validateChanges validates cfg. When prev, a configuration that passed
+// validation, is given, only the sections that changed since, the cross-section
+// rules referencing them, and validations depending on outside state such as
+// files are rerun; anything else falls back to full validation. Failures are
+// reported to w.
+func validateChanges(w io.Writer, prev, cfg *config.Config) error {
+	validate := config.NewValidator()
+	sections, full := config.RevalidationScope(prev, cfg)
+	mode := "full"
+	if !full {
+		mode = "incremental"
+	}
+	start := time.Now()
+	defer func() {
+		metricsRecorder.ObserveValidation(mode, time.Since(start))
+		if full {
+			debugf("validated all sections in %s", time.Since(start).Round(time.Microsecond))
+		} else {
+			debugf("revalidated sections %v in %s", sections, time.Since(start).Round(time.Microsecond))
+		}
+	}()
+
+	var err error
+	if full {
+		err = validate.Struct(cfg)
+	} else {
+		err = config.ValidateSections(validate, cfg, sections)
+	}
+	if err != nil {
 		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			fmt.Fprintln(os.Stderr, "Configuration validation failed:")
+			fmt.Fprintln(w, "Configuration validation failed:")
 			for _, fieldErr := range validationErrors {
-				// Use Namespace to show the full path (e.g., "Config.Server.Port" instead of just "Port")
-				fieldPath := fieldErr.Namespace()
-				tag := fieldErr.Tag()
-				currentValue := fieldErr.Value()
-				param := fieldErr.Param()
+				reportFieldError(w, fieldErr, fieldErr.Namespace())
+			}
+		} else {
+			fmt.Fprintf(w, "  %v\n", err)
+		}
+		return err
+	}
 
-				fmt.Fprintf(os.Stderr, "  - Field '%s' validation failed\n", fieldPath)
-				fmt.Fprintf(os.Stderr, "    Current value: %v (type: %T)\n", currentValue, currentValue)
+	if !full && !slices.Contains(sections, "extensions") {
+		return nil
+	}
+	if err := config.ValidateExtensions(cfg.Extensions, validate); err != nil {
+		var extErr *config.ExtensionError
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &extErr) && errors.As(err, &validationErrors) {
+			fmt.Fprintf(w, "Extension '%s' validation failed:\n", extErr.Name)
+			for _, fieldErr := range validationErrors {
+				reportFieldError(w, fieldErr, extErr.Key(fieldErr.StructNamespace()))
+			}
+		}
+		return err
+	}
 
-				// Provide detailed error messages based on validation tag
-				switch tag {
-				case "required":
-					fmt.Fprintln(os.Stderr, "    Expected: non-empty value")
-					if fieldErr.Field() == "Name" {
-						fmt.Fprintln(os.Stderr, "    Hint: Application name is mandatory. Provide it via:")
-						fmt.Fprintln(os.Stderr, "      • Flag: --app-name or -n")
-						fmt.Fprintln(os.Stderr, "      • Environment variable: MYAPP_APP_NAME")
-						fmt.Fprintln(os.Stderr, "      • Config file: app.name")
-					}
+	return nil
+}
+
+// reportFieldError prints a single validation failure to w with a hint about the
+// expected value; fieldPath is the name shown to the user
+func reportFieldError(w io.Writer, fieldErr validator.FieldError, fieldPath string) {
+	tag := fieldErr.Tag()
+	currentValue := fieldErr.Value()
+	param := fieldErr.Param()
+
+	fmt.Fprintf(w, "  - Field '%s' validation failed\n", fieldPath)
+	fmt.Fprintf(w, "    Current value: %v (type: %T)\n", currentValue, currentValue)
+
+	// Provide detailed error messages based on validation tag
+	switch tag {
+	case "required":
+		fmt.Fprintln(w, "    Expected: non-empty value")
+		if fieldErr.Field() == "Name" {
+			fmt.Fprintln(w, "    Hint: Application name is mandatory. Provide it via:")
+			fmt.Fprintln(w, "      • Flag: --app-name or -n")
+			fmt.Fprintln(w, "      • Environment variable: MYAPP_APP_NAME")
+			fmt.Fprintln(w, "      • Config file: app.name")
+		}
 
-				case "min":
-					fmt.Fprintf(os.Stderr, "    Expected: minimum value of %s\n", param)
+	case "min":
+		fmt.Fprintf(w, "    Expected: minimum value of %s\n", param)
 
-				case "max":
-					fmt.Fprintf(os.Stderr, "    Expected: maximum value of %s\n", param)
+	case "max":
+		fmt.Fprintf(w, "    Expected: maximum value of %s\n", param)
 
-				case "lte":
-					fmt.Fprintf(os.Stderr, "    Expected: value less than or equal to %s\n", param)
+	case "lte":
+		fmt.Fprintf(w, "    Expected: value less than or equal to %s\n", param)
 
-				case "gte":
-					fmt.Fprintf(os.Stderr, "    Expected: value greater than or equal to %s\n", param)
+	case "gte":
+		fmt.Fprintf(w, "    Expected: value greater than or equal to %s\n", param)
 
-				case "lt":
-					fmt.Fprintf(os.Stderr, "    Expected: value less than %s\n", param)
+	case "lt":
+		fmt.Fprintf(w, "    Expected: value less than %s\n", param)
 
-				case "gt":
-					fmt.Fprintf(os.Stderr, "    Expected: value greater than %s\n", param)
+	case "gt":
+		fmt.Fprintf(w, "    Expected: value greater than %s\n", param)
 
-				case "oneof":
-					fmt.Fprintf(os.Stderr, "    Expected: one of [%s]\n", param)
+	case "gtefield":
+		fmt.Fprintf(w, "    Expected: value greater than or equal to field %s\n", param)
 
-				case "email":
-					fmt.Fprintln(os.Stderr, "    Expected: valid email address format")
+	case "gtfield":
+		fmt.Fprintf(w, "    Expected: value greater than field %s\n", param)
 
-				case "url":
-					fmt.Fprintln(os.Stderr, "    Expected: valid URL format")
+	case "ltefield":
+		fmt.Fprintf(w, "    Expected: value less than or equal to field %s\n", param)
 
-				case "len":
-					fmt.Fprintf(os.Stderr, "    Expected: length of %s\n", param)
+	case "ltfield":
+		fmt.Fprintf(w, "    Expected: value less than field %s\n", param)
 
-				case "eq":
-					fmt.Fprintf(os.Stderr, "    Expected: value equal to %s\n", param)
+	case "required_with":
+		fmt.Fprintf(w, "    Expected: non-empty value when %s is set\n", param)
 
-				case "ne":
-					fmt.Fprintf(os.Stderr, "    Expected: value not equal to %s\n", param)
+	case "file":
+		fmt.Fprintln(w, "    Expected: path to an existing file")
 
-				default:
-					fmt.Fprintf(os.Stderr, "    Validation rule: %s", tag)
-					if param != "" {
-						fmt.Fprintf(os.Stderr, " (parameter: %s)", param)
-					}
-					fmt.Fprintln(os.Stderr)
-				}
-			}
+	case "oneof":
+		fmt.Fprintf(w, "    Expected: one of [%s]\n", param)
+
+	case "email":
+		fmt.Fprintln(w, "    Expected: valid email address format")
+
+	case "url":
+		fmt.Fprintln(w, "    Expected: valid URL format")
+
+	case "len":
+		fmt.Fprintf(w, "    Expected: length of %s\n", param)
+
+	case "rule":
+		fmt.Fprintf(w, "    Expected: %s\n", param)
+
+	case "pattern":
+		if p, ok := config.LookupPattern(param); ok {
+			fmt.Fprintf(w, "    Expected: %s\n", p.Description)
+			fmt.Fprintf(w, "    Pattern: %s (%s)\n", p.Name, p.Expr)
 		} else {
-			fmt.Fprintf(os.Stderr, "  %v\n", err)
+			fmt.Fprintf(w, "    Expected: value matching unknown pattern %s\n", param)
 		}
-		return err
-	}
 
-	return nil
-}
+	case "eq":
+		fmt.Fprintf(w, "    Expected: value equal to %s\n", param)
 
-// displayConfiguration loads, validates, and displays the configuration as JSON
-func displayConfiguration() {
-	cfg, err := loadAndValidateConfig()
-	if err != nil {
-		os.Exit(1)
-	}
+	case "ne":
+		fmt.Fprintf(w, "    Expected: value not equal to %s\n", param)
 
-	// Marshal configuration to JSON with indentation
-	jsonData, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling config to JSON: %v\n", err)
-		return
+	default:
+		fmt.Fprintf(w, "    Validation rule: %s", tag)
+		if param != "" {
+			fmt.Fprintf(w, " (parameter: %s)", param)
+		}
+		fmt.Fprintln(w)
 	}
+}
 
-	fmt.Println(string(jsonData))
+// displayConfiguration loads, validates, and displays the configuration in the
+// --output format, JSON by default, with secrets treated according to --secrets
+func displayConfiguration(w io.Writer) {
+	p := mustNewPrinter(displayOutput)
+	displaySecrets.mustCheck()
+	cfg := mustLoadConfig()
+	mustPrint(w, p, displaySecrets.scrub(cfg))
 }
@@ -261,3 +261,39 @@ logging:
 		})
 	}
 }
+
+func TestFlagGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "Password And Password File",
+			args:    []string{"--db-password=secret", "--db-password-file=/run/secrets/db"},
+			wantErr: "none of the others can be",
+		},
+		{
+			name:    "TLS Cert Without Key",
+			args:    []string{"--tls-cert=server.crt"},
+			wantErr: "they must all be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer rootCmd.Flags().VisitAll(func(f *pflag.Flag) {
+				if f.Changed {
+					f.Value.Set(f.DefValue)
+					f.Changed = false
+				}
+			})
+
+			rootCmd.SetArgs(tt.args)
+			err := rootCmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
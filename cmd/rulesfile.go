@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+// rulesFileName is the rules file looked up next to the config file
+const rulesFileName = "rules.yaml"
+
+// rulesFile overrides the location of the rules file
+var rulesFile string
+
+// rulesFileFor returns --rules, or rules.yaml next to configFile, or rules.yaml in
+// the current directory when configFile is empty. explicit is set for --rules,
+// which must exist.
+func rulesFileFor(configFile string) (path string, explicit bool) {
+	if rulesFile != "" {
+		return rulesFile, true
+	}
+	return filepath.Join(filepath.Dir(configFile), rulesFileName), false
+}
+
+// checkRulesFile evaluates the operator-supplied rules file for configFile
+// against cfg. Warnings are printed to w and the load goes on, unless
+// --strict-warnings promotes them; errors are printed in detail and returned as
+// a *config.RulesError.
+func checkRulesFile(w io.Writer, cfg *config.Config, configFile string) error {
+	path, explicit := rulesFileFor(configFile)
+	rules, err := config.LoadRulesFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error loading rules file: %w", err)
+	}
+
+	var failed []config.Violation
+	var warnings []string
+	for _, violation := range config.CheckFileRules(cfg, rules) {
+		if violation.Severity == config.SeverityWarning {
+			warnings = append(warnings, fmt.Sprintf("%s: %s (current value: %v)", violation.Key, violation.Message, violation.Value))
+			continue
+		}
+		failed = append(failed, violation)
+	}
+	warningsErr := reportWarnings(w, warnings)
+	if len(failed) == 0 {
+		return warningsErr
+	}
+
+	fmt.Fprintf(w, "Rules file %s validation failed:\n", path)
+	for _, violation := range failed {
+		fmt.Fprintf(w, "  - Key '%s' violates rule %s\n", violation.Key, violation.Rule)
+		fmt.Fprintf(w, "    Current value: %v\n", violation.Value)
+		fmt.Fprintf(w, "    Expected: %s\n", violation.Message)
+	}
+	return &config.RulesError{Path: path, Violations: failed}
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Long: `Prints a JSON Schema (draft 2020-12) for the configuration file, generated from
+the same struct tags used for validation: allowed values, numeric bounds, named
+patterns, and the schemas of registered extensions. Point your editor's YAML or
+JSON language server at it to get completion and inline validation.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(config.Schema()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding schema: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(schemaCmd)
+}
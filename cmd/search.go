@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/spf13/cobra"
+)
+
+var searchOpts struct {
+	regex      bool
+	ignoreCase bool
+	keysOnly   bool
+	valuesOnly bool
+	format     string
+}
+
+// searchMatch is a configuration key whose name or value matched a search
+type searchMatch struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <pattern>",
+	Short: "Search the effective configuration for matching keys or values",
+	Long: `Searches the keys and values of the effective configuration, extensions included,
+and prints every match with its dotted key and the source providing it, such as
+flag, env, or file. The pattern is a substring unless --regex is given.
+
+Secret values are redacted and never matched, so a search cannot reveal them;
+secret keys are still found by name. Exits with status 1 when nothing matches.
+
+  myapp config search 8080
+  myapp config search --regex --keys-only 'listen$'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		match, err := searchMatcher(args[0], searchOpts.regex, searchOpts.ignoreCase)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg := mustLoadConfig()
+
+		matches := searchConfig(cfg, match, !searchOpts.valuesOnly, !searchOpts.keysOnly)
+		switch searchOpts.format {
+		case "json":
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(matches); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding matches: %v\n", err)
+				os.Exit(1)
+			}
+		case "table":
+			if len(matches) > 0 {
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+				for _, m := range matches {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", m.Key, orDash(m.Value), m.Source)
+				}
+				w.Flush()
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown format %q (expected table or json)\n", searchOpts.format)
+			os.Exit(1)
+		}
+		if len(matches) == 0 {
+			fmt.Fprintf(os.Stderr, "No keys or values match %q\n", args[0])
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	searchCmd.Flags().BoolVarP(&searchOpts.regex, "regex", "E", false, "treat the pattern as a regular expression")
+	searchCmd.Flags().BoolVarP(&searchOpts.ignoreCase, "ignore-case", "i", false, "match regardless of case")
+	searchCmd.Flags().BoolVar(&searchOpts.keysOnly, "keys-only", false, "match keys only")
+	searchCmd.Flags().BoolVar(&searchOpts.valuesOnly, "values-only", false, "match values only")
+	searchCmd.MarkFlagsMutuallyExclusive("keys-only", "values-only")
+	searchCmd.Flags().StringVar(&searchOpts.format, "format", "table", "output format: table or json")
+	searchCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	configCmd.AddCommand(searchCmd)
+}
+
+// searchMatcher compiles a search pattern: a substring, or a regular expression
+// with regex set
+func searchMatcher(pattern string, regex, ignoreCase bool) (func(string) bool, error) {
+	if !regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re.MatchString, nil
+}
+
+// searchConfig returns the settings of cfg whose key (with keys set) or value
+// (with values set) matches, sorted by key. Secret values are redacted and only
+// their keys are matched.
+func searchConfig(cfg *config.Config, match func(string) bool, keys, values bool) []searchMatch {
+	matches := []searchMatch{}
+	add := func(key string, value any, secret bool) {
+		text := fmt.Sprint(value)
+		if secret && text != "" {
+			text = config.Redacted
+		}
+		if (keys && match(key)) || (values && !secret && match(text)) {
+			matches = append(matches, searchMatch{Key: key, Value: text, Source: keySource(key)})
+		}
+	}
+	for _, s := range cfg.Settings() {
+		add(s.Key, s.Value, s.Secret)
+	}
+	extensions := map[string]any{}
+	flattenSettings("extensions", cfg.Extensions, extensions)
+	for key, value := range extensions {
+		add(key, value, false)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Key < matches[j].Key })
+	return matches
+}
+
+// flattenSettings adds the leaves of nested settings to flat under dotted keys
+func flattenSettings(prefix string, settings map[string]any, flat map[string]any) {
+	for name, value := range settings {
+		key := strings.ToLower(name)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			flattenSettings(key, nested, flat)
+			continue
+		}
+		flat[key] = value
+	}
+}
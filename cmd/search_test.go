@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+func TestSearchConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.Port = 8080
+	cfg.Database.Port = 5432
+	cfg.Database.Password = "port8080"
+	cfg.Extensions = map[string]any{"cache": map[string]any{"ttl": "8080s"}}
+
+	match, err := searchMatcher("8080", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := searchConfig(cfg, match, true, true)
+	want := []string{"extensions.cache.ttl", "server.port"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected matches %v, got %+v", want, got)
+	}
+	for i, m := range got {
+		if m.Key != want[i] {
+			t.Errorf("Expected match %d to be %s, got %s", i, want[i], m.Key)
+		}
+	}
+
+	match, _ = searchMatcher("PASSWORD$", true, true)
+	got = searchConfig(cfg, match, true, true)
+	if len(got) != 1 || got[0].Key != "database.password" || got[0].Value != config.Redacted {
+		t.Errorf("Expected the secret key to match with a redacted value, got %+v", got)
+	}
+	if got := searchConfig(cfg, match, false, true); len(got) != 0 {
+		t.Errorf("Expected no value matches, got %+v", got)
+	}
+
+	match, _ = searchMatcher(`^(server|database)\.port$`, true, false)
+	if got := searchConfig(cfg, match, true, false); len(got) != 2 {
+		t.Errorf("Expected both port keys to match, got %+v", got)
+	}
+	if _, err := searchMatcher("(", true, false); err == nil {
+		t.Error("Expected an invalid regex to fail")
+	}
+}
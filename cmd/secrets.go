@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/example/cobra-viper-demo/audit"
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/example/cobra-viper-demo/decrypt"
+)
+
+// secretKeys returns the keys of the fields tagged secret:"true"
+func secretKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for _, f := range config.Fields() {
+		if f.Secret {
+			keys[f.Key] = true
+		}
+	}
+	return keys
+}
+
+// resolvePasswordFile loads database.password from database.password_file. The
+// file content is trimmed of trailing newlines, as written by most secret mounts.
+func resolvePasswordFile(cfg *config.Config) error {
+	path := cfg.Database.PasswordFile
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		auditLog.Record(audit.Event{Type: audit.EventSecretResolved, Outcome: audit.OutcomeFailure, Secret: "database.password", Source: path, Reasons: []string{err.Error()}})
+		return fmt.Errorf("reading database password file: %w", err)
+	}
+	cfg.Database.Password = strings.TrimRight(string(data), "\r\n")
+	auditLog.Record(audit.Event{Type: audit.EventSecretResolved, Outcome: audit.OutcomeSuccess, Secret: "database.password", Source: path})
+	return nil
+}
+
+// keyProvider supplies the age identities for inline "enc:" values
+var keyProvider decrypt.KeyProvider = decrypt.EnvKeyProvider{}
+
+// decryptInlineValues replaces every inline "enc:" value with its plaintext.
+// It runs before validation so constraints apply to the decrypted values.
+func decryptInlineValues(cfg *config.Config) error {
+	return cfg.RewriteStrings(func(key, value string) (string, error) {
+		if !decrypt.IsEncryptedValue(value) {
+			return value, nil
+		}
+		plaintext, err := decrypt.DecryptValue(value, keyProvider)
+		if err != nil {
+			auditLog.Record(audit.Event{Type: audit.EventSecretResolved, Outcome: audit.OutcomeFailure, Secret: key, Source: "inline", Reasons: []string{err.Error()}})
+			return "", fmt.Errorf("decrypting %s: %w", key, err)
+		}
+		auditLog.Record(audit.Event{Type: audit.EventSecretResolved, Outcome: audit.OutcomeSuccess, Secret: key, Source: "inline"})
+		return plaintext, nil
+	})
+}
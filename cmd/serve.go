@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/example/cobra-viper-demo/metrics"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the HTTP server and reload configuration when the file changes",
+	Long: `Starts an HTTP server on server.host:server.port. The configuration file is
+watched for changes; every change is validated and only applied when valid, so a
+broken edit never replaces a working configuration. Listener addresses are read
+once at startup and require a restart to change.
+
+GET /healthz reports the config subsystem state: last load time, source summary,
+config hash, and "degraded" status when the latest reload was rejected. On
+shutdown it answers 503 "draining" for server.shutdown.drain_timeout before the
+servers stop accepting connections.
+
+When --config names a file in a git repository, the repository is polled every
+--git-poll-interval and new commits are reloaded like local edits.
+
+ConfigMaps and Secrets read with --k8s-configmap and --k8s-secret are watched
+through the Kubernetes API, and every change goes through the same validated reload.
+
+When metrics.enabled is set, Prometheus metrics are served on metrics.listen at /metrics.
+When admin.enabled is set, GET /config on admin.listen returns the running
+configuration with secrets redacted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runServe(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe() error {
+	cfg := mustLoadConfig()
+	live := newLiveConfig(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 3)
+	warn := serveWarning(errCh)
+	var servers []*http.Server
+
+	if cfg.Metrics.Enabled {
+		metricsRecorder = metrics.New()
+		metricsRecorder.ObserveLoad(true, cfg.Hash(), nil)
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metricsRecorder.Handler())
+		servers = append(servers, startServer(cfg.Metrics.Listen, mux, config.TLSConfig{}, errCh))
+		fmt.Fprintf(os.Stderr, "Serving metrics on %s/metrics\n", cfg.Metrics.Listen)
+	}
+
+	if cfg.Admin.Enabled {
+		mux := http.NewServeMux()
+		mux.Handle("/config", adminConfigHandler(live))
+		servers = append(servers, startServer(cfg.Admin.Listen, mux, config.TLSConfig{}, errCh))
+		fmt.Fprintf(os.Stderr, "Serving admin endpoint on %s/config\n", cfg.Admin.Listen)
+	}
+
+	if gitConfig != nil {
+		// The checkout is replaced on new commits, so poll the repository instead
+		// of watching the file
+		if gitPollInterval > 0 {
+			go pollGitConfig(ctx, *gitConfig, gitPollInterval, func(commit string) {
+				live.reloadAll(gitConfig.Repo + "@" + shortCommit(commit))
+			}, warn)
+			fmt.Fprintf(os.Stderr, "Polling %s every %s\n", gitConfig.Repo, gitPollInterval)
+		}
+	} else if file := v.ConfigFileUsed(); file != "" {
+		if err := watchConfigFile(ctx, file, live.reloadAll, warn); err != nil {
+			if err := reportWarnings(os.Stderr, []string{fmt.Sprintf("not watching %s: %v", file, err)}); err != nil {
+				return err
+			}
+		}
+	}
+	watchKubeObjects(ctx, live.reloadAll, warn)
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", healthHandler(live))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		current := live.Get()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(current.App)
+	})
+	addr := net.JoinHostPort(cfg.Server.Host, strconv.Itoa(cfg.Server.Port))
+	servers = append(servers, startServer(addr, withTimeouts(mux, cfg.Server), cfg.Server.TLS, errCh))
+	scheme := "http"
+	if cfg.Server.TLS.Cert != "" {
+		scheme = "https"
+	}
+	fmt.Fprintf(os.Stderr, "Listening on %s://%s\n", scheme, addr)
+
+	select {
+	case <-ctx.Done():
+		stop()
+	case err := <-errCh:
+		return err
+	}
+	return shutdown(live.Get().Server.Shutdown, live, servers)
+}
+
+// serveWarning returns a function reporting warnings raised while serving, such
+// as failed polls and watch errors. Under --strict-warnings a warning stops the
+// server through errCh.
+func serveWarning(errCh chan<- error) func(warning string) {
+	return func(warning string) {
+		if err := reportWarnings(os.Stderr, []string{warning}); err != nil {
+			select {
+			case errCh <- fmt.Errorf("%s: %w", warning, err):
+			default:
+			}
+		}
+	}
+}
+
+// shutdown stops the servers gracefully. For the drain timeout /healthz reports
+// draining while requests are still served, so load balancers stop routing new
+// traffic. Then every server stops accepting connections at once, and in-flight
+// requests get until the end of the grace period before their connections are
+// closed forcibly, with a warning. A second signal skips the remaining drain and
+// grace period.
+func shutdown(cfg config.ShutdownConfig, live *liveConfig, servers []*http.Server) error {
+	fmt.Fprintf(os.Stderr, "Shutting down (drain timeout %s, grace period %s)\n", cfg.DrainTimeout, cfg.GracePeriod)
+
+	graceCtx, cancelGrace := context.WithTimeout(context.Background(), cfg.GracePeriod)
+	defer cancelGrace()
+	graceCtx, stop := signal.NotifyContext(graceCtx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	live.drain()
+	select {
+	case <-time.After(cfg.DrainTimeout):
+	case <-graceCtx.Done():
+	}
+
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Shutdown(graceCtx); err != nil {
+				errs[i] = fmt.Errorf("shutting down %s: %w", srv.Addr, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		warnErr := reportWarnings(os.Stderr, []string{fmt.Sprintf("%v; closing remaining connections", err)})
+		for _, srv := range servers {
+			srv.Close()
+		}
+		return warnErr
+	}
+	return nil
+}
+
+// startServer starts an HTTP server in the background, serving HTTPS when a TLS
+// certificate is configured, and reports listen errors on errCh
+func startServer(addr string, handler http.Handler, tls config.TLSConfig, errCh chan<- error) *http.Server {
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		var err error
+		if tls.Cert != "" {
+			err = srv.ListenAndServeTLS(tls.Cert, tls.Key)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- fmt.Errorf("listening on %s: %w", addr, err)
+		}
+	}()
+	return srv
+}
+
+// withTimeouts bounds request handling by server.timeout when it is set
+func withTimeouts(h http.Handler, cfg config.ServerConfig) http.Handler {
+	if cfg.Timeout <= 0 {
+		return h
+	}
+	return http.TimeoutHandler(h, time.Duration(cfg.Timeout)*time.Second, "request timed out")
+}
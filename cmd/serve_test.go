@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+// slowServer serves requests that take delay to answer on a local port
+func slowServer(t *testing.T, delay time.Duration) (*http.Server, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+	})}
+	go srv.Serve(ln)
+	return srv, "http://" + ln.Addr().String()
+}
+
+// startRequests sends a request to each URL in the background, reporting its
+// error, and returns once all of them are in flight
+func startRequests(urls ...string) []chan error {
+	results := make([]chan error, len(urls))
+	for i, url := range urls {
+		results[i] = make(chan error, 1)
+		go func() {
+			resp, err := http.Get(url)
+			if err == nil {
+				resp.Body.Close()
+			}
+			results[i] <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	return results
+}
+
+func TestShutdownDrainsEveryServer(t *testing.T) {
+	first, firstURL := slowServer(t, 300*time.Millisecond)
+	second, secondURL := slowServer(t, 300*time.Millisecond)
+	live := newLiveConfig(&config.Config{})
+	requests := startRequests(firstURL, secondURL)
+
+	start := time.Now()
+	err := shutdown(config.ShutdownConfig{DrainTimeout: 50 * time.Millisecond, GracePeriod: 2 * time.Second}, live, []*http.Server{first, second})
+	if err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	// Sequential shutdowns would take both handler delays
+	if elapsed := time.Since(start); elapsed >= 550*time.Millisecond {
+		t.Errorf("Expected servers to shut down concurrently, took %s", elapsed)
+	}
+	for i, result := range requests {
+		if err := <-result; err != nil {
+			t.Errorf("Expected in-flight request %d to finish within the grace period, got %v", i, err)
+		}
+	}
+	if status := live.health().Status; status != healthDraining {
+		t.Errorf("Expected health status %q, got %q", healthDraining, status)
+	}
+}
+
+func TestShutdownClosesAfterGracePeriod(t *testing.T) {
+	t.Setenv(strictWarningsEnv, "false")
+	srv, url := slowServer(t, 10*time.Second)
+	requests := startRequests(url)
+
+	start := time.Now()
+	err := shutdown(config.ShutdownConfig{DrainTimeout: 50 * time.Millisecond, GracePeriod: 300 * time.Millisecond}, newLiveConfig(&config.Config{}), []*http.Server{srv})
+	if err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	// The grace period, not the drain timeout, bounds in-flight requests
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Expected shutdown to take about the grace period, took %s", elapsed)
+	}
+	if err := <-requests[0]; err == nil {
+		t.Error("Expected the request outliving the grace period to be cut off")
+	}
+}
+
+func TestShutdownCutoffFailsUnderStrictWarnings(t *testing.T) {
+	t.Setenv(strictWarningsEnv, "true")
+	srv, url := slowServer(t, 10*time.Second)
+	requests := startRequests(url)
+
+	err := shutdown(config.ShutdownConfig{GracePeriod: 100 * time.Millisecond}, newLiveConfig(&config.Config{}), []*http.Server{srv})
+	var warningsErr *warningsError
+	if !errors.As(err, &warningsErr) {
+		t.Errorf("Expected cutting off connections to fail under strict warnings, got %v", err)
+	}
+	<-requests[0]
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/spf13/cobra"
+)
+
+// snapshot is the on-disk format of a saved effective configuration
+type snapshot struct {
+	Metadata snapshotMetadata `json:"metadata"`
+	Config   *config.Config   `json:"config"`
+}
+
+type snapshotMetadata struct {
+	CreatedAt  time.Time         `json:"created_at"`
+	ConfigFile string            `json:"config_file,omitempty"`
+	Sources    map[string]string `json:"sources"`
+	Secrets    string            `json:"secrets,omitempty"`
+	Hash       string            `json:"hash"`
+}
+
+var snapshotOpts struct {
+	output  string
+	force   bool
+	secrets secretsOptions
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save, restore, and compare snapshots of the effective configuration",
+	Long: `Snapshots capture the effective configuration together with metadata (timestamp,
+the source of every key, and a content hash). They are useful as pre-upgrade
+backups and for post-incident forensics. Snapshots are written with owner-only
+permissions.`,
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save <file>",
+	Short: "Save the effective configuration to a snapshot file",
+	Long: `Saves the effective configuration to a snapshot file. Secret fields are redacted
+by default; use --secrets omit to leave them out, or --secrets include
+--confirm-secrets to keep their real values in the snapshot.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshotOpts.secrets.mustCheck()
+		cfg := mustLoadConfig()
+
+		snap, err := newSnapshot(cfg, snapshotOpts.secrets)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := writeSnapshot(args[0], snap); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing snapshot: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Snapshot saved to %s (hash %s)\n", args[0], snap.Metadata.Hash)
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Write the configuration stored in a snapshot back out as YAML",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		snap := mustReadSnapshot(args[0])
+
+		restored := snap.Config
+		if snap.Metadata.Secrets == secretsRedact || snap.Metadata.Secrets == secretsOmit {
+			// Never write the redaction marker back as a real value
+			restored = secretsOptions{secrets: secretsOmit}.scrub(snap.Config)
+			fmt.Fprintf(os.Stderr, "Note: the snapshot was saved with --secrets %s; secret fields are left empty\n", snap.Metadata.Secrets)
+		}
+		data, err := restored.YAML()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if out := snapshotOpts.output; out != "" && out != "-" && !snapshotOpts.force {
+			if _, err := os.Stat(out); err == nil {
+				fmt.Fprintf(os.Stderr, "Error: %s already exists (use --force to overwrite)\n", out)
+				os.Exit(1)
+			}
+		}
+		if err := writeOutput(snapshotOpts.output, data, 0o600); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing configuration: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var snapshotCompareCmd = &cobra.Command{
+	Use:   "compare <file>",
+	Short: "Compare the effective configuration against a snapshot",
+	Long: `Compares the effective configuration against a snapshot and lists every key
+that changed since the snapshot was taken. Exits with status 1 when they differ.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		snap := mustReadSnapshot(args[0])
+		cfg := mustLoadConfig()
+
+		changes, err := compareSnapshot(snap, cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error comparing with snapshot: %v\n", err)
+			os.Exit(1)
+		}
+		if len(changes) == 0 {
+			fmt.Printf("No differences from snapshot taken at %s\n", snap.Metadata.CreatedAt.Format(time.RFC3339))
+			return
+		}
+		fmt.Printf("%d difference(s) from snapshot taken at %s:\n", len(changes), snap.Metadata.CreatedAt.Format(time.RFC3339))
+		for _, c := range changes {
+			fmt.Printf("  %s: %v -> %v\n", c.Key, c.Old, c.New)
+		}
+		os.Exit(1)
+	},
+}
+
+func init() {
+	snapshotRestoreCmd.Flags().StringVarP(&snapshotOpts.output, "output", "o", "", "write the restored configuration to this file instead of stdout")
+	snapshotRestoreCmd.Flags().BoolVar(&snapshotOpts.force, "force", false, "overwrite the output file if it exists")
+	snapshotOpts.secrets.addFlags(snapshotSaveCmd, snapshotSaveCmd.Flags())
+
+	snapshotCmd.AddCommand(snapshotSaveCmd, snapshotRestoreCmd, snapshotCompareCmd)
+	configCmd.AddCommand(snapshotCmd)
+}
+
+// newSnapshot captures cfg with the source of every key, treating secret
+// fields according to secrets
+func newSnapshot(cfg *config.Config, secrets secretsOptions) (snapshot, error) {
+	if err := secrets.check(); err != nil {
+		return snapshot{}, err
+	}
+	cfg = secrets.scrub(cfg)
+	sources := make(map[string]string)
+	for _, field := range config.Fields() {
+		sources[field.Key] = keySource(field.Key)
+	}
+	return snapshot{
+		Metadata: snapshotMetadata{
+			CreatedAt:  time.Now().UTC(),
+			ConfigFile: v.ConfigFileUsed(),
+			Sources:    sources,
+			Secrets:    secrets.secrets,
+			Hash:       cfg.Hash(),
+		},
+		Config: cfg,
+	}, nil
+}
+
+// writeSnapshot writes a snapshot as indented JSON, readable only by the owner
+func writeSnapshot(path string, snap snapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// compareSnapshot lists the changes from the snapshot to cfg. Secret fields of
+// cfg are treated the way the snapshot treated them. A snapshot reads extension
+// numbers back as float64, so cfg goes through the same JSON round trip first;
+// otherwise an unchanged 60 would be reported as 60 -> 60.
+func compareSnapshot(snap *snapshot, cfg *config.Config) ([]config.Change, error) {
+	if snap.Metadata.Secrets != "" {
+		cfg = secretsOptions{secrets: snap.Metadata.Secrets}.scrub(cfg)
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var current config.Config
+	if err := json.Unmarshal(data, &current); err != nil {
+		return nil, err
+	}
+	return snap.Config.Diff(&current), nil
+}
+
+// readSnapshot loads a snapshot file and verifies its content hash
+func readSnapshot(path string) (*snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	if snap.Config == nil {
+		return nil, errors.New("snapshot has no configuration")
+	}
+	if hash := snap.Config.Hash(); hash != snap.Metadata.Hash {
+		return nil, fmt.Errorf("snapshot %s is corrupted: hash %s does not match recorded %s", path, hash, snap.Metadata.Hash)
+	}
+	return &snap, nil
+}
+
+func mustReadSnapshot(path string) *snapshot {
+	snap, err := readSnapshot(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading snapshot: %v\n", err)
+		os.Exit(1)
+	}
+	return snap
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+func snapshotConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.App.Name = "demo"
+	cfg.Server.Port = 8080
+	cfg.Extensions = map[string]any{"cache": map[string]any{"ttl": 60, "nodes": []any{"a", "b"}}}
+	return cfg
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snap.json")
+	saved, err := newSnapshot(snapshotConfig(), secretsOptions{secrets: secretsInclude, confirmSecrets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSnapshot(path, saved); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("Expected an owner-only snapshot file, got %v, %v", info.Mode(), err)
+	}
+	snap, err := readSnapshot(path)
+	if err != nil {
+		t.Fatalf("readSnapshot failed: %v", err)
+	}
+
+	// Extension ints read back as float64 must not count as changes
+	changes, err := compareSnapshot(snap, snapshotConfig())
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Expected no changes against the same config, got %v, %v", changes, err)
+	}
+
+	changed := snapshotConfig()
+	changed.Server.Port = 9000
+	changed.Extensions["cache"].(map[string]any)["ttl"] = 120
+	changes, err = compareSnapshot(snap, changed)
+	want := []config.Change{
+		{Key: "server.port", Old: 8080, New: 9000},
+		{Key: "extensions.cache.ttl", Old: float64(60), New: float64(120)},
+	}
+	if err != nil || !reflect.DeepEqual(changes, want) {
+		t.Errorf("compareSnapshot = %v, %v, want %v", changes, err, want)
+	}
+
+	data, err := snap.Config.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"  name: demo\n", "  port: 8080\n", "    ttl: 60\n"} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("Expected %q in restored YAML:\n%s", line, data)
+		}
+	}
+}
+
+func TestReadSnapshotDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snap.json")
+	snap, err := newSnapshot(snapshotConfig(), secretsOptions{secrets: secretsRedact})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSnapshot(path, snap); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), `"port": 8080`, `"port": 8081`, 1)
+	if tampered == string(data) {
+		t.Fatal("Expected the snapshot to contain the server port")
+	}
+	os.WriteFile(path, []byte(tampered), 0o600)
+
+	if _, err := readSnapshot(path); err == nil || !strings.Contains(err.Error(), "corrupted") {
+		t.Errorf("Expected a hash mismatch, got %v", err)
+	}
+}
+
+func TestSnapshotRedactsSecrets(t *testing.T) {
+	cfg := snapshotConfig()
+	cfg.Database.Password = "hunter2"
+
+	if _, err := newSnapshot(cfg, secretsOptions{secrets: secretsInclude}); err == nil {
+		t.Error("Expected --secrets include without --confirm-secrets to be rejected")
+	}
+
+	path := filepath.Join(t.TempDir(), "snap.json")
+	snap, err := newSnapshot(cfg, secretsOptions{secrets: secretsRedact})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSnapshot(path, snap); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "hunter2") || !strings.Contains(string(data), config.Redacted) {
+		t.Errorf("Expected the password to be redacted in the snapshot:\n%s", data)
+	}
+	if cfg.Database.Password != "hunter2" {
+		t.Error("Expected the live config to keep its password")
+	}
+
+	read, err := readSnapshot(path)
+	if err != nil {
+		t.Fatalf("readSnapshot failed: %v", err)
+	}
+	if changes, err := compareSnapshot(read, cfg); err != nil || len(changes) != 0 {
+		t.Errorf("Expected a redacted password not to count as a change, got %v, %v", changes, err)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+// Names of the places a configuration value can come from
+const (
+	sourceFlag       = "flag"
+	sourceEnv        = "env"
+	sourceExtensions = "extensions.d"
+	sourceKube       = "kubernetes"
+	sourceRegistry   = "registry"
+	sourceFile       = "file"
+	sourceDefault    = "default"
+)
+
+// keySource reports which source provides the effective value of a viper key,
+// following viper's precedence and the overlay merge order:
+// flag > env > extensions.d > kubernetes > registry > file > default
+func keySource(viperKey string) string {
+	if flagName, ok := flagBindings[viperKey]; ok {
+		if f := rootCmd.PersistentFlags().Lookup(flagName); f != nil && f.Changed {
+			return sourceFlag
+		}
+	}
+	// Viper ignores empty environment variables
+	if os.Getenv(envVarName(viperKey)) != "" {
+		return sourceEnv
+	}
+	if extensionsKeys[viperKey] {
+		return sourceExtensions
+	}
+	if kubeKeys[viperKey] {
+		return sourceKube
+	}
+	if registryKeys[viperKey] {
+		return sourceRegistry
+	}
+	if v.InConfig(viperKey) {
+		return sourceFile
+	}
+	return sourceDefault
+}
+
+// sourceSummary describes where the effective configuration came from
+type sourceSummary struct {
+	ConfigFile string         `json:"config_file,omitempty"`
+	Keys       map[string]int `json:"keys"` // number of keys provided by each source
+}
+
+// summarizeSources counts the configuration keys provided by each source
+func summarizeSources() sourceSummary {
+	summary := sourceSummary{ConfigFile: v.ConfigFileUsed(), Keys: map[string]int{}}
+	for _, field := range config.Fields() {
+		summary.Keys[keySource(field.Key)]++
+	}
+	return summary
+}
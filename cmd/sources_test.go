@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeySourceIgnoresEmptyEnv(t *testing.T) {
+	name := envVarName("logging.level")
+
+	t.Setenv(name, "")
+	if got := keySource("logging.level"); got == sourceEnv {
+		t.Errorf("Expected an empty %s not to count as the source, got %s", name, got)
+	}
+	t.Setenv(name, "debug")
+	if got := keySource("logging.level"); got != sourceEnv {
+		t.Errorf("Expected source %s with %s set, got %s", sourceEnv, name, got)
+	}
+}
+
+func TestInitConfigReadsExtensionlessConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte("app:\n  name: extensionless\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	t.Setenv("MYAPP_CONFIG", "")
+	useViper(t, nil)
+	saved := cfgFile
+	defer func() { cfgFile = saved }()
+	cfgFile = ""
+
+	initConfig()
+	if got := v.GetString("app.name"); got != "extensionless" {
+		t.Errorf("Expected app.name from the extensionless ./config, got %q", got)
+	}
+	if got := keySource("app.name"); got != sourceFile {
+		t.Errorf("Expected source %s, got %s", sourceFile, got)
+	}
+}
+
+func TestKeySourceReportsExtensions(t *testing.T) {
+	useViper(t, nil)
+	if err := v.MergeConfigMap(map[string]any{"extensions": map[string]any{"cache": map[string]any{"ttl": 30}, "search": map[string]any{"limit": 10}}}); err != nil {
+		t.Fatal(err)
+	}
+	applyExtensions("extensions.d", map[string]any{"cache": map[string]any{"ttl": 60}}, nil)
+
+	if got := keySource("extensions.cache.ttl"); got != sourceExtensions {
+		t.Errorf("Expected an extensions.d key to come from %s, got %s", sourceExtensions, got)
+	}
+	if got := keySource("extensions.search.limit"); got != sourceFile {
+		t.Errorf("Expected an extension set in the config file to come from %s, got %s", sourceFile, got)
+	}
+
+	applyExtensions("extensions.d", nil, nil)
+	if got := keySource("extensions.cache.ttl"); got != sourceFile {
+		t.Errorf("Expected removed fragments to stop counting as a source, got %s", got)
+	}
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+var templateOpts struct {
+	values     []string
+	output     string
+	format     string
+	noValidate bool
+}
+
+var templateCmd = &cobra.Command{
+	Use:   "template <file>",
+	Short: "Render a templated config file and validate the result",
+	Long: `Renders a config file written as a Go text/template and validates the result with
+the same decoding, validation, and rules the application applies at load time, so
+CI can produce environment-specific config artifacts that are known to load.
+
+The template sees .Values, merged from the --values files in order, and .Env, the
+process environment. Functions:
+
+  env "NAME"            environment variable, empty when unset
+  required "msg" value  fails rendering when value is empty
+  default "d" value     value, or d when value is empty
+  quote value           value as a double-quoted string
+  lower / upper         change case
+
+Referencing a missing key in .Values fails rendering. The format is taken from
+--format, the output file, or the template name without .tmpl (config.yaml.tmpl
+renders YAML). The document is validated on its own: flags and environment
+variables of this invocation do not apply, only the defaults.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rendered, err := renderTemplate(args[0], templateOpts.values)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+
+		if !templateOpts.noValidate {
+			format := templateFormat(args[0], templateOpts.output, templateOpts.format)
+			if err := validateDocument(os.Stderr, rendered, format, v.ConfigFileUsed()); err != nil {
+				var validationErrors validator.ValidationErrors
+				var rulesErr *config.RulesError
+				if !errors.As(err, &validationErrors) && !errors.As(err, &rulesErr) {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				fmt.Fprintln(os.Stderr, "Rendered configuration is invalid")
+				os.Exit(1)
+			}
+		}
+
+		if err := writeOutput(templateOpts.output, rendered, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// renderTemplate executes the template at path with the merged values files
+func renderTemplate(path string, valuesFiles []string) ([]byte, error) {
+	values := map[string]any{}
+	for _, file := range valuesFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var layer map[string]any
+		if err := yaml.Unmarshal(data, &layer); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		mergeValues(values, layer)
+	}
+
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if k, val, ok := strings.Cut(kv, "="); ok {
+			env[k] = val
+		}
+	}
+
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(filepath.Base(path)).
+		Option("missingkey=error").
+		Funcs(templateFuncs).
+		Parse(string(text))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]any{"Values": values, "Env": env}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var templateFuncs = template.FuncMap{
+	"env": os.Getenv,
+	"required": func(msg string, value any) (any, error) {
+		if value == nil || fmt.Sprint(value) == "" {
+			return nil, errors.New(msg)
+		}
+		return value, nil
+	},
+	"default": func(def, value any) any {
+		if value == nil || fmt.Sprint(value) == "" {
+			return def
+		}
+		return value
+	},
+	"quote": func(value any) string {
+		return fmt.Sprintf("%q", fmt.Sprint(value))
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// mergeValues deep-merges src into dst; later values files override earlier ones
+func mergeValues(dst, src map[string]any) {
+	for k, val := range src {
+		if nested, ok := val.(map[string]any); ok {
+			if existing, ok := dst[k].(map[string]any); ok {
+				mergeValues(existing, nested)
+				continue
+			}
+		}
+		dst[k] = val
+	}
+}
+
+// templateFormat picks the config format of the rendered document
+func templateFormat(templatePath, outputPath, format string) string {
+	if format != "" {
+		return format
+	}
+	if outputPath != "" && outputPath != "-" {
+		return strings.TrimPrefix(filepath.Ext(outputPath), ".")
+	}
+	name := templatePath
+	for _, ext := range []string{".tmpl", ".tpl", ".gotmpl"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return strings.TrimPrefix(filepath.Ext(name), ".")
+}
+
+// validateDocument decodes a standalone config document and validates it the
+// way a load does: flag defaults, strict decoding, inline decryption, struct
+// and extension validation, the rules file found for configFile, and
+// deprecation warnings. Failures are reported to w.
+func validateDocument(w io.Writer, data []byte, format, configFile string) error {
+	if format == "" {
+		return errors.New("cannot tell the config format; use --format")
+	}
+	dv := viper.NewWithOptions(viper.WithCodecRegistry(newCodecRegistry()))
+	dv.SetConfigType(format)
+	for key := range flagBindings {
+		dv.SetDefault(key, defaultValue(key))
+	}
+	if err := dv.ReadConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("parsing %s: %w", format, err)
+	}
+
+	var cfg config.Config
+	if err := dv.UnmarshalExact(&cfg); err != nil {
+		return fmt.Errorf("error unmarshaling config: %w", err)
+	}
+	if err := decryptInlineValues(&cfg); err != nil {
+		return err
+	}
+	if err := validateConfig(w, &cfg); err != nil {
+		return err
+	}
+	if err := checkRulesFile(w, &cfg, configFile); err != nil {
+		return err
+	}
+	return reportWarnings(w, deprecationWarnings(config.Fields(), dv.InConfig))
+}
+
+func init() {
+	templateCmd.Flags().StringArrayVar(&templateOpts.values, "values", nil, "YAML values file; repeatable, later files override earlier ones")
+	templateCmd.Flags().StringVarP(&templateOpts.output, "output", "o", "", "output file (default stdout)")
+	templateCmd.Flags().StringVar(&templateOpts.format, "format", "", "format of the rendered config: yaml, json, toml, properties, or ini")
+	templateCmd.Flags().BoolVar(&templateOpts.noValidate, "no-validate", false, "skip validation of the rendered config")
+	configCmd.AddCommand(templateCmd)
+}
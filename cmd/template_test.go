@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tmpl := write("config.yaml.tmpl", `server:
+  port: {{ .Values.server.port }}
+  host: {{ env "TEMPLATE_TEST_HOST" | default "0.0.0.0" }}
+app:
+  name: {{ .Values.app.name | quote }}
+`)
+	base := write("base.yaml", "server:\n  port: 8080\napp:\n  name: demo\n")
+	prod := write("prod.yaml", "server:\n  port: 8443\n")
+	t.Setenv("TEMPLATE_TEST_HOST", "")
+
+	out, err := renderTemplate(tmpl, []string{base, prod})
+	if err != nil {
+		t.Fatalf("renderTemplate failed: %v", err)
+	}
+	for _, want := range []string{"port: 8443", "host: 0.0.0.0", `name: "demo"`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Expected %q in rendered output:\n%s", want, out)
+		}
+	}
+	if err := validateDocument(io.Discard, out, templateFormat(tmpl, "", ""), ""); err != nil {
+		t.Errorf("Expected rendered config to validate, got %v", err)
+	}
+
+	if _, err := renderTemplate(tmpl, []string{prod}); err == nil {
+		t.Error("Expected an error for a missing value")
+	}
+}
+
+func TestTemplateFormat(t *testing.T) {
+	tests := []struct {
+		template, output, format, want string
+	}{
+		{"config.yaml.tmpl", "", "", "yaml"},
+		{"config.json.tpl", "", "", "json"},
+		{"config.tmpl", "out.toml", "", "toml"},
+		{"config.yaml.tmpl", "out.toml", "json", "json"},
+	}
+	for _, tt := range tests {
+		if got := templateFormat(tt.template, tt.output, tt.format); got != tt.want {
+			t.Errorf("templateFormat(%q, %q, %q) = %q, want %q", tt.template, tt.output, tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cobra"
+)
+
+var validateOpts struct {
+	recursive bool
+	jobs      int
+	format    string
+}
+
+// fileResult is the outcome of validating one config file
+type fileResult struct {
+	path   string
+	report string // validation failures and warnings, as printed for a load
+	err    error
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <file or pattern>...",
+	Short: "Validate config files without loading them",
+	Long: `Validates config files the way the application validates its configuration at
+load time: strict decoding, inline decryption, struct and extension validation,
+and the rules.yaml next to each file (or --rules). Each file is validated on its
+own; flags and environment variables of this invocation do not apply, only the
+defaults.
+
+Arguments are files or glob patterns. With --recursive, ** in a pattern matches
+any number of directories. Files are validated concurrently by --jobs workers;
+a pass or fail line is printed for each, followed by a summary. Exits with status
+1 when any file fails.
+
+  myapp validate --recursive 'deploy/**/config*.yaml'`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if validateOpts.jobs < 1 {
+			fmt.Fprintln(os.Stderr, "Error: --jobs must be at least 1")
+			os.Exit(1)
+		}
+		files, err := expandPatterns(args, validateOpts.recursive)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		results := validateFiles(files, validateOpts.format, validateOpts.jobs)
+		failed := printFileResults(cmd.OutOrStdout(), results)
+		fmt.Fprintf(cmd.OutOrStdout(), "\n%d file(s) validated: %d passed, %d failed\n", len(results), len(results)-failed, failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	validateCmd.Flags().BoolVarP(&validateOpts.recursive, "recursive", "r", false, "let ** in patterns match any number of directories")
+	validateCmd.Flags().IntVarP(&validateOpts.jobs, "jobs", "j", runtime.NumCPU(), "number of files validated concurrently")
+	validateCmd.Flags().StringVar(&validateOpts.format, "format", "", "format of every file: yaml, json, toml, properties, or ini (default from each file extension)")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// expandPatterns returns the files matched by args, sorted and without
+// duplicates. An argument without glob characters names a file that must exist.
+func expandPatterns(args []string, recursive bool) ([]string, error) {
+	seen := map[string]bool{}
+	var files []string
+	for _, arg := range args {
+		var matches []string
+		var err error
+		switch {
+		case !hasGlobMeta(arg):
+			var info os.FileInfo
+			if info, err = os.Stat(arg); err == nil && info.IsDir() {
+				err = fmt.Errorf("%s is a directory; use a pattern such as '%s/**/*.yaml' with --recursive", arg, filepath.ToSlash(filepath.Clean(arg)))
+			}
+			matches = []string{arg}
+		case recursive:
+			matches, err = globRecursive(arg)
+		default:
+			matches, err = filepath.Glob(arg)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", arg)
+		}
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// hasGlobMeta reports whether a pattern holds glob characters
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// globRecursive returns the files matching a pattern in which a ** segment
+// matches any number of directories, such as deploy/**/config*.yaml
+func globRecursive(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	segments := strings.Split(pattern, "/")
+
+	// Walk from the longest leading directory without glob characters
+	fixed := 0
+	for fixed < len(segments)-1 && !hasGlobMeta(segments[fixed]) {
+		fixed++
+	}
+	root := strings.Join(segments[:fixed], "/")
+	switch {
+	case root == "" && strings.HasPrefix(pattern, "/"):
+		root = "/"
+	case root == "":
+		root = "."
+	}
+
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == filepath.FromSlash(root) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.IsDir() && matchSegments(segments, strings.Split(filepath.ToSlash(p), "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// matchSegments matches path segments against pattern segments, where ** stands
+// for zero or more segments and any other segment is a path.Match pattern
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchSegments(pattern[1:], name[1:])
+}
+
+// validateFiles validates files with a pool of jobs workers, returning the
+// results in the order of files
+func validateFiles(files []string, format string, jobs int) []fileResult {
+	results := make([]fileResult, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = validateFile(files[i], format)
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// validateFile validates one config file, capturing what a load would print
+func validateFile(file, format string) fileResult {
+	result := fileResult{path: file}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+	}
+	var report bytes.Buffer
+	result.err = validateDocument(&report, data, format, file)
+	result.report = report.String()
+	return result
+}
+
+// printFileResults prints a pass or fail line for each result, with its report
+// indented below, and returns the number of failures
+func printFileResults(w io.Writer, results []fileResult) int {
+	failed := 0
+	for _, r := range results {
+		status := "PASS"
+		if r.err != nil {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(w, "%s  %s\n", status, r.path)
+		report := r.report
+		var validationErrors validator.ValidationErrors
+		var rulesErr *config.RulesError
+		if r.err != nil && !errors.As(r.err, &validationErrors) && !errors.As(r.err, &rulesErr) {
+			report += fmt.Sprintf("Error: %v\n", r.err)
+		}
+		for _, line := range strings.Split(strings.TrimRight(report, "\n"), "\n") {
+			if line != "" {
+				fmt.Fprintf(w, "      %s\n", line)
+			}
+		}
+	}
+	return failed
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGlobRecursive(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"config.yaml", "a/config.yaml", "a/b/config-prod.yaml", "a/b/values.yaml", "c/config.json"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := expandPatterns([]string{filepath.Join(dir, "**", "config*.yaml")}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "a/b/config-prod.yaml"), filepath.Join(dir, "a/config.yaml"), filepath.Join(dir, "config.yaml")}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	got, err = expandPatterns([]string{filepath.Join(dir, "*", "config.*")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{filepath.Join(dir, "a/config.yaml"), filepath.Join(dir, "c/config.json")}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	for _, args := range [][]string{{dir}, {filepath.Join(dir, "missing.yaml")}, {filepath.Join(dir, "**", "*.toml")}} {
+		if _, err := expandPatterns(args, true); err == nil {
+			t.Errorf("Expected expandPatterns(%q) to fail", args)
+		}
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"good.yaml":    "app:\n  name: demo\nserver:\n  port: 8080\n",
+		"bad.yaml":     "app:\n  name: demo\nserver:\n  port: 80\n",
+		"broken.yaml":  "app: [\n",
+		"unknown.yaml": "app:\n  name: demo\n  nmae: typo\n",
+		"good.json":    `{"app": {"name": "demo"}, "server": {"port": 8080}}`,
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	results := validateFiles(paths, "", 3)
+	if len(results) != len(paths) {
+		t.Fatalf("Expected %d results, got %d", len(paths), len(results))
+	}
+	for i, r := range results {
+		if r.path != paths[i] {
+			t.Errorf("Expected result %d for %s, got %s", i, paths[i], r.path)
+		}
+		wantPass := filepath.Base(r.path) == "good.yaml" || filepath.Base(r.path) == "good.json"
+		if (r.err == nil) != wantPass {
+			t.Errorf("%s: expected pass=%v, got error %v", filepath.Base(r.path), wantPass, r.err)
+		}
+	}
+	if failed := printFileResults(io.Discard, results); failed != 3 {
+		t.Errorf("Expected 3 failures, got %d", failed)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+// strictWarningsEnv turns on --strict-warnings when the flag is not given
+const strictWarningsEnv = envPrefix + "_STRICT_WARNINGS"
+
+var (
+	// strictWarnings is the --strict-warnings flag
+	strictWarnings bool
+
+	// earlyWarnings counts the warnings reported before the flags were parsed,
+	// when only MYAPP_STRICT_WARNINGS could be honored
+	earlyWarnings int
+)
+
+// warningsError fails a load whose warnings --strict-warnings promoted to errors;
+// the warnings themselves have already been printed
+type warningsError struct {
+	count int
+}
+
+func (e *warningsError) Error() string {
+	return fmt.Sprintf("%d warning(s) treated as errors (--strict-warnings)", e.count)
+}
+
+// strictWarningsEnabled reports whether warnings fail the load: --strict-warnings
+// when given, or else MYAPP_STRICT_WARNINGS
+func strictWarningsEnabled() bool {
+	if f := rootCmd.PersistentFlags().Lookup("strict-warnings"); f != nil && f.Changed {
+		return strictWarnings
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv(strictWarningsEnv))
+	return enabled
+}
+
+// reportWarnings prints warnings to w. Under --strict-warnings any warning fails
+// the load with a *warningsError.
+func reportWarnings(w io.Writer, warnings []string) error {
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+	if len(warnings) > 0 && strictWarningsEnabled() {
+		return &warningsError{count: len(warnings)}
+	}
+	return nil
+}
+
+// deprecationWarnings returns a warning for every deprecated field that isSet
+// reports as set, so configurations move off deprecated keys
+func deprecationWarnings(fields []config.Field, isSet func(key string) bool) []string {
+	var warnings []string
+	for _, field := range fields {
+		if field.Deprecated != "" && isSet(field.Key) {
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated: %s", field.Key, field.Deprecated))
+		}
+	}
+	return warnings
+}
+
+// earlyWarning reports a warning raised before the flags are parsed, such as a
+// missing help translation
+func earlyWarning(warning string) error {
+	earlyWarnings++
+	return reportWarnings(os.Stderr, []string{warning})
+}
+
+// checkEarlyWarnings fails under --strict-warnings when a deprecated flag
+// spelling was used or a warning was reported before the flags were parsed;
+// pflag and earlyWarning have already printed them
+func checkEarlyWarnings() {
+	if !strictWarningsEnabled() {
+		return
+	}
+	used := earlyWarnings
+	for alias := range flagAliases {
+		if f := rootCmd.PersistentFlags().Lookup(alias); f != nil && f.Changed {
+			used++
+		}
+	}
+	if used > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", &warningsError{count: used})
+		os.Exit(1)
+	}
+}
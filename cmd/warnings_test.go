@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/cobra-viper-demo/config"
+)
+
+func TestReportWarnings(t *testing.T) {
+	warnings := []string{"server.port: ports below 8000 are reserved"}
+
+	t.Setenv(strictWarningsEnv, "")
+	var out bytes.Buffer
+	if err := reportWarnings(&out, warnings); err != nil {
+		t.Errorf("Expected warnings not to fail by default, got %v", err)
+	}
+	if got, want := out.String(), "Warning: server.port: ports below 8000 are reserved\n"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	t.Setenv(strictWarningsEnv, "true")
+	var warningsErr *warningsError
+	if err := reportWarnings(io.Discard, warnings); !errors.As(err, &warningsErr) || warningsErr.count != 1 {
+		t.Errorf("Expected a warningsError for 1 warning, got %v", err)
+	}
+	if err := reportWarnings(io.Discard, nil); err != nil {
+		t.Errorf("Expected no error without warnings, got %v", err)
+	}
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	fields := []config.Field{
+		{Key: "server.timeout", Deprecated: "use server.shutdown.grace_period instead"},
+		{Key: "server.port"},
+		{Key: "logging.format", Deprecated: "set by the platform"},
+	}
+	set := map[string]bool{"server.timeout": true, "server.port": true}
+	got := deprecationWarnings(fields, func(key string) bool { return set[key] })
+	want := "server.timeout is deprecated: use server.shutdown.grace_period instead"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("Expected [%q], got %q", want, got)
+	}
+}
+
+func TestCheckRulesFileStrictWarnings(t *testing.T) {
+	dir := t.TempDir()
+	rules := "rules:\n  - key: server.port\n    operator: gte\n    value: 8000\n    severity: warning\n"
+	if err := os.WriteFile(filepath.Join(dir, rulesFileName), []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Server.Port = 2000
+	configFile := filepath.Join(dir, "config.yaml")
+
+	t.Setenv(strictWarningsEnv, "false")
+	if err := checkRulesFile(io.Discard, cfg, configFile); err != nil {
+		t.Errorf("Expected a warning rule not to fail the load, got %v", err)
+	}
+	t.Setenv(strictWarningsEnv, "1")
+	var warningsErr *warningsError
+	if err := checkRulesFile(io.Discard, cfg, configFile); !errors.As(err, &warningsErr) {
+		t.Errorf("Expected --strict-warnings to fail the load, got %v", err)
+	}
+}
+
+func TestOpenAuditLogStrictWarnings(t *testing.T) {
+	useViper(t, map[string]any{"audit.enabled": true, "audit.sink": "file", "audit.path": filepath.Join(t.TempDir(), "missing", "audit.log")})
+	saved := auditLog
+	defer func() { auditLog = saved }()
+	auditLog = nil
+
+	t.Setenv(strictWarningsEnv, "false")
+	if err := openAuditLog(); err != nil || auditLog != nil {
+		t.Errorf("Expected audit logging to be disabled with a warning, got %v", err)
+	}
+	t.Setenv(strictWarningsEnv, "true")
+	var warningsErr *warningsError
+	if err := openAuditLog(); !errors.As(err, &warningsErr) {
+		t.Errorf("Expected a warningsError under strict warnings, got %v", err)
+	}
+}
+
+func TestServeWarningStopsServerWhenStrict(t *testing.T) {
+	errCh := make(chan error, 1)
+	warn := serveWarning(errCh)
+
+	t.Setenv(strictWarningsEnv, "false")
+	warn("watching Secret/myapp: connection reset")
+	if len(errCh) != 0 {
+		t.Errorf("Expected warnings not to stop the server by default, got %v", <-errCh)
+	}
+
+	t.Setenv(strictWarningsEnv, "true")
+	warn("watching Secret/myapp: connection reset")
+	warn("polling https://host/config.git: timeout")
+	var warningsErr *warningsError
+	if err := <-errCh; !errors.As(err, &warningsErr) {
+		t.Errorf("Expected a warningsError under strict warnings, got %v", err)
+	}
+}
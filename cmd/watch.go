@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchConfigFile calls onChange with the event's file name whenever the config
+// file is written or replaced, until ctx is done, and passes watch errors to warn. Viper's WatchConfig re-reads
+// the file on its own goroutine, outside reloadMu; this watcher leaves the
+// re-read to onChange. Like viper, it watches the directory, so editors that
+// save by renaming and Kubernetes volumes that swap a symlink are noticed.
+func watchConfigFile(ctx context.Context, path string, onChange func(name string), warn func(warning string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	file := filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		watcher.Close()
+		return err
+	}
+	target, _ := filepath.EvalSymlinks(file)
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				current, _ := filepath.EvalSymlinks(file)
+				written := filepath.Clean(event.Name) == file && event.Has(fsnotify.Write|fsnotify.Create)
+				if written || (current != "" && current != target) {
+					target = current
+					onChange(event.Name)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				warn(fmt.Sprintf("watching %s: %v", file, err))
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeConfig replaces the config file atomically, as editors and Kubernetes
+// volume updates do
+func writeConfig(t *testing.T, path string, port int) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("app:\n  name: demo\nserver:\n  port: %d\n", port)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Run with -race: file changes and remote sources reload concurrently, and every
+// re-read of viper must happen under reloadMu
+func TestFileAndRemoteReloadsAreSerialized(t *testing.T) {
+	t.Setenv(configCacheEnv, "")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, 8080)
+	useViper(t, nil)
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := unmarshalAndValidate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := newLiveConfig(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := watchConfigFile(ctx, path, live.reloadAll, func(string) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 10 {
+			live.reloadAll("Secret/myapp")
+		}
+	}()
+	for port := 8081; port <= 8090; port++ {
+		writeConfig(t, path, port)
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for live.Get().Server.Port != 8090 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the last file change to be applied, got port %d", live.Get().Server.Port)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchConfigFileIgnoresOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeConfig(t, path, 8080)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan string, 10)
+	if err := watchConfigFile(ctx, path, func(name string) { changes <- name }, func(string) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("unrelated"), 0o644)
+	select {
+	case name := <-changes:
+		t.Fatalf("Expected no reload for another file, got %s", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	writeConfig(t, path, 8081)
+	select {
+	case name := <-changes:
+		if filepath.Clean(name) != path {
+			t.Errorf("Expected a change of %s, got %s", path, name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a reload after the config file changed")
+	}
+}
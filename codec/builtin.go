@@ -0,0 +1,38 @@
+package codec
+
+import (
+	"encoding/json"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// YAML, JSON and TOML mirror viper's built-in codecs, which are not exported,
+// so they can be wrapped by Decrypting
+
+// YAML decodes and encodes YAML documents
+type YAML struct{}
+
+// Decode parses YAML content into v
+func (YAML) Decode(b []byte, v map[string]any) error { return yaml.Unmarshal(b, &v) }
+
+// Encode renders v as YAML
+func (YAML) Encode(v map[string]any) ([]byte, error) { return yaml.Marshal(v) }
+
+// JSON decodes and encodes JSON documents
+type JSON struct{}
+
+// Decode parses JSON content into v
+func (JSON) Decode(b []byte, v map[string]any) error { return json.Unmarshal(b, &v) }
+
+// Encode renders v as indented JSON
+func (JSON) Encode(v map[string]any) ([]byte, error) { return json.MarshalIndent(v, "", "  ") }
+
+// TOML decodes and encodes TOML documents
+type TOML struct{}
+
+// Decode parses TOML content into v
+func (TOML) Decode(b []byte, v map[string]any) error { return toml.Unmarshal(b, &v) }
+
+// Encode renders v as TOML
+func (TOML) Encode(v map[string]any) ([]byte, error) { return toml.Marshal(v) }
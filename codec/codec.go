@@ -0,0 +1,72 @@
+// Package codec provides viper codecs for configuration formats that viper does
+// not support out of the box: Java-style .properties and INI files. Both map
+// dotted or sectioned keys onto the same nested structure as the YAML file.
+package codec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// setPath stores value in settings at the dotted key, creating nested maps.
+// Keys are lowercased, matching how viper treats keys from other formats.
+func setPath(settings map[string]any, key string, value any) error {
+	parts := strings.Split(strings.ToLower(key), ".")
+	m := settings
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := m[part]
+		if !ok {
+			nested := map[string]any{}
+			m[part] = nested
+			m = nested
+			continue
+		}
+		nested, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("key %q conflicts with value at %q", key, strings.Join(parts[:i+1], "."))
+		}
+		m = nested
+	}
+	leaf := parts[len(parts)-1]
+	if _, ok := m[leaf].(map[string]any); ok {
+		return fmt.Errorf("key %q conflicts with section of the same name", key)
+	}
+	m[leaf] = value
+	return nil
+}
+
+// flatten returns the dotted leaf keys of a nested map in sorted order
+func flatten(prefix string, settings map[string]any, out map[string]string) {
+	for k, v := range settings {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]any); ok {
+			flatten(key, nested, out)
+			continue
+		}
+		out[key] = formatValue(v)
+	}
+}
+
+func formatValue(v any) string {
+	if list, ok := v.([]any); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
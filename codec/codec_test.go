@@ -0,0 +1,113 @@
+package codec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPropertiesDecode(t *testing.T) {
+	input := `# comment
+! another comment
+app.name = My App
+app.version:1.0.0
+server.port 8080
+server.shutdown.grace_period=30s
+database.password = p\=ss\:word
+logging.format = multi \
+    line
+key\ with\ spaces = unicode é
+`
+	got := map[string]any{}
+	if err := (Properties{}).Decode([]byte(input), got); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	want := map[string]any{
+		"app": map[string]any{"name": "My App", "version": "1.0.0"},
+		"server": map[string]any{
+			"port":     "8080",
+			"shutdown": map[string]any{"grace_period": "30s"},
+		},
+		"database":        map[string]any{"password": "p=ss:word"},
+		"logging":         map[string]any{"format": "multi line"},
+		"key with spaces": "unicode é",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected result:\n got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestPropertiesDecodeConflict(t *testing.T) {
+	input := "server=localhost\nserver.port=8080\n"
+	if err := (Properties{}).Decode([]byte(input), map[string]any{}); err == nil {
+		t.Error("Expected error for key conflicting with a section")
+	}
+}
+
+func TestINIDecode(t *testing.T) {
+	input := `; top-level comment
+debug = true
+
+[app]
+name = "My App"
+environment = production ; inline comment
+
+[server]
+port = 8080
+
+[server.shutdown]
+drain_timeout = 10s
+`
+	got := map[string]any{}
+	if err := (INI{}).Decode([]byte(input), got); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	want := map[string]any{
+		"debug": "true",
+		"app":   map[string]any{"name": "My App", "environment": "production"},
+		"server": map[string]any{
+			"port":     "8080",
+			"shutdown": map[string]any{"drain_timeout": "10s"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected result:\n got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestINIDecodeErrors(t *testing.T) {
+	for _, input := range []string{"[server\nport=1\n", "[]\n", "[app]\njust-a-word\n"} {
+		if err := (INI{}).Decode([]byte(input), map[string]any{}); err == nil {
+			t.Errorf("Expected error decoding %q", input)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	settings := map[string]any{
+		"app":    map[string]any{"name": "My App; v2", "version": "1.0.0"},
+		"server": map[string]any{"port": 8080, "shutdown": map[string]any{"grace_period": "30s"}},
+	}
+	want := map[string]any{
+		"app":    map[string]any{"name": "My App; v2", "version": "1.0.0"},
+		"server": map[string]any{"port": "8080", "shutdown": map[string]any{"grace_period": "30s"}},
+	}
+
+	for name, codec := range map[string]interface {
+		Encode(map[string]any) ([]byte, error)
+		Decode([]byte, map[string]any) error
+	}{"properties": Properties{}, "ini": INI{}} {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Encode(settings)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			got := map[string]any{}
+			if err := codec.Decode(data, got); err != nil {
+				t.Fatalf("Decode failed: %v\n%s", err, data)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Round trip mismatch:\n got %#v\nwant %#v\nencoded:\n%s", got, want, data)
+			}
+		})
+	}
+}
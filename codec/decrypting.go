@@ -0,0 +1,27 @@
+package codec
+
+import "github.com/example/cobra-viper-demo/decrypt"
+
+// Codec is the decoder/encoder pair viper expects for a config format
+type Codec interface {
+	Decode(b []byte, v map[string]any) error
+	Encode(v map[string]any) ([]byte, error)
+}
+
+// Decrypting wraps a codec so that age- or SOPS-encrypted documents are
+// decrypted before decoding. Plaintext documents pass through unchanged, and
+// encoding always produces plaintext.
+type Decrypting struct {
+	Codec
+	// Format is the config type passed to decrypt.Decrypt, e.g. "yaml"
+	Format string
+}
+
+// Decode decrypts b if needed and decodes it with the wrapped codec
+func (d Decrypting) Decode(b []byte, v map[string]any) error {
+	plain, err := decrypt.Decrypt(b, d.Format)
+	if err != nil {
+		return err
+	}
+	return d.Codec.Decode(plain, v)
+}
@@ -0,0 +1,120 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// INI decodes and encodes INI files. Section names map to top-level keys and may
+// be dotted for deeper nesting, e.g. "[server.shutdown]".
+type INI struct{}
+
+// Decode parses INI content into v
+func (INI) Decode(b []byte, v map[string]any) error {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	section := ""
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("ini line %d: unterminated section header", lineNo)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == "" {
+				return fmt.Errorf("ini line %d: empty section name", lineNo)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, ok = strings.Cut(line, ":")
+		}
+		if !ok {
+			return fmt.Errorf("ini line %d: expected key = value", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return fmt.Errorf("ini line %d: missing key", lineNo)
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		if err := setPath(v, key, iniValue(strings.TrimSpace(value))); err != nil {
+			return fmt.Errorf("ini line %d: %w", lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Encode writes top-level values first, then one section per nested map
+func (INI) Encode(v map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	var sections []string
+	for _, key := range sortedKeys(v) {
+		if _, ok := v[key].(map[string]any); ok {
+			sections = append(sections, key)
+			continue
+		}
+		fmt.Fprintf(&buf, "%s = %s\n", key, quoteINI(formatValue(v[key])))
+	}
+
+	for _, name := range sections {
+		encodeINISection(&buf, name, v[name].(map[string]any))
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeINISection(buf *bytes.Buffer, name string, settings map[string]any) {
+	var nested []string
+	var values []string
+	for _, key := range sortedKeys(settings) {
+		if _, ok := settings[key].(map[string]any); ok {
+			nested = append(nested, key)
+		} else {
+			values = append(values, key)
+		}
+	}
+	if len(values) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(buf, "[%s]\n", name)
+		for _, key := range values {
+			fmt.Fprintf(buf, "%s = %s\n", key, quoteINI(formatValue(settings[key])))
+		}
+	}
+	for _, key := range nested {
+		encodeINISection(buf, name+"."+key, settings[key].(map[string]any))
+	}
+}
+
+// iniValue strips matching surrounding quotes and trailing inline comments
+func iniValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	for _, marker := range []string{" ;", " #"} {
+		if i := strings.Index(value, marker); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+// quoteINI quotes values that would otherwise lose whitespace or be read as comments
+func quoteINI(value string) string {
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value, ";#") {
+		return `"` + value + `"`
+	}
+	return value
+}
@@ -0,0 +1,176 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Properties decodes and encodes Java-style .properties files. Dotted keys such
+// as "server.port=8080" become nested settings.
+type Properties struct{}
+
+// Decode parses properties content into v
+func (Properties) Decode(b []byte, v map[string]any) error {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	var logical strings.Builder
+	lineNo, startLine := 0, 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if logical.Len() == 0 {
+			line = strings.TrimLeft(line, " \t\f")
+			if line == "" || line[0] == '#' || line[0] == '!' {
+				continue
+			}
+			startLine = lineNo
+		} else {
+			// continuation lines drop their leading whitespace
+			line = strings.TrimLeft(line, " \t\f")
+		}
+
+		if continues(line) {
+			logical.WriteString(line[:len(line)-1])
+			continue
+		}
+		logical.WriteString(line)
+
+		key, value, err := splitProperty(logical.String())
+		logical.Reset()
+		if err != nil {
+			return fmt.Errorf("properties line %d: %w", startLine, err)
+		}
+		if err := setPath(v, key, value); err != nil {
+			return fmt.Errorf("properties line %d: %w", startLine, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if logical.Len() > 0 {
+		key, value, err := splitProperty(logical.String())
+		if err != nil {
+			return fmt.Errorf("properties line %d: %w", startLine, err)
+		}
+		return setPath(v, key, value)
+	}
+	return nil
+}
+
+// Encode writes v as sorted dotted key=value lines
+func (Properties) Encode(v map[string]any) ([]byte, error) {
+	flat := map[string]string{}
+	flatten("", v, flat)
+
+	var buf bytes.Buffer
+	for _, key := range sortedKeys(flat) {
+		fmt.Fprintf(&buf, "%s=%s\n", escapeProperty(key, true), escapeProperty(flat[key], false))
+	}
+	return buf.Bytes(), nil
+}
+
+// continues reports whether a line ends with an odd number of backslashes
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty separates key and value: the key ends at the first unescaped
+// '=', ':' or whitespace, and one separator plus surrounding whitespace is skipped
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	if key == "" {
+		return "", "", errors.New("missing key")
+	}
+	value, err := unescapeProperty(rest)
+	return key, value, err
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 >= len(s) {
+				return "", fmt.Errorf("malformed \\u escape in %q", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape in %q", s)
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '=' || r == ':' || r == '#' || r == '!':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == ' ' && (isKey || i == 0):
+			b.WriteString(`\ `)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package config
+
+import "reflect"
+
+// Clone returns a deep copy of the configuration, including the Extensions map,
+// so a candidate config can be modified without aliasing the live snapshot
+func (c *Config) Clone() *Config {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	if c.Extensions != nil {
+		out.Extensions = deepCopy(c.Extensions).(map[string]any)
+	}
+	return &out
+}
+
+// deepCopy recursively copies maps, slices and pointers; other values are returned as-is
+func deepCopy(src any) any {
+	if src == nil {
+		return nil
+	}
+	return deepCopyValue(reflect.ValueOf(src)).Interface()
+}
+
+func deepCopyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), deepCopyElem(iter.Value(), v.Type().Elem()))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopyElem(v.Index(i), v.Type().Elem()))
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(deepCopyValue(v.Elem()))
+		return out
+	default:
+		return v
+	}
+}
+
+// deepCopyElem copies a container element, unwrapping interface values so that
+// nested map[string]any and []any structures are copied as well
+func deepCopyElem(v reflect.Value, elemType reflect.Type) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Zero(elemType)
+		}
+		copied := deepCopyValue(v.Elem())
+		out := reflect.New(elemType).Elem()
+		out.Set(copied)
+		return out
+	}
+	return deepCopyValue(v)
+}
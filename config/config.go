@@ -1,10 +1,16 @@
 package config
 
+import "time"
+
 type Config struct {
-	App      AppConfig      `mapstructure:"app" json:"app" validate:"required"`
-	Server   ServerConfig   `mapstructure:"server" json:"server"`
-	Database DatabaseConfig `mapstructure:"database" json:"database"`
-	Logging  LoggingConfig  `mapstructure:"logging" json:"logging"`
+	App        AppConfig      `mapstructure:"app" json:"app" validate:"required"`
+	Server     ServerConfig   `mapstructure:"server" json:"server"`
+	Database   DatabaseConfig `mapstructure:"database" json:"database"`
+	Logging    LoggingConfig  `mapstructure:"logging" json:"logging"`
+	Audit      AuditConfig    `mapstructure:"audit" json:"audit"`
+	Metrics    MetricsConfig  `mapstructure:"metrics" json:"metrics"`
+	Admin      AdminConfig    `mapstructure:"admin" json:"admin"`
+	Extensions map[string]any `mapstructure:"extensions" json:"extensions,omitempty"`
 }
 
 type AppConfig struct {
@@ -14,20 +20,56 @@ type AppConfig struct {
 }
 
 type ServerConfig struct {
-	Host    string `mapstructure:"host" json:"host"`
-	Port    int    `mapstructure:"port" json:"port" validate:"gte=1024,lte=9000"`
-	Timeout int    `mapstructure:"timeout" json:"timeout"`
+	Host     string         `mapstructure:"host" json:"host"`
+	Port     int            `mapstructure:"port" json:"port" validate:"gte=1024,lte=9000"`
+	Timeout  int            `mapstructure:"timeout" json:"timeout"`
+	Shutdown ShutdownConfig `mapstructure:"shutdown" json:"shutdown"`
+	TLS      TLSConfig      `mapstructure:"tls" json:"tls"`
+}
+
+// TLSConfig enables HTTPS in serve mode; certificate and key must be set together
+type TLSConfig struct {
+	Cert string `mapstructure:"cert" json:"cert" validate:"required_with=Key,omitempty,file"`
+	Key  string `mapstructure:"key" json:"key" validate:"required_with=Cert,omitempty,file"`
+}
+
+// ShutdownConfig controls graceful shutdown in serve mode: /healthz reports
+// draining for DrainTimeout while requests are still served, and in-flight
+// requests are cut off once GracePeriod has passed since the signal.
+type ShutdownConfig struct {
+	GracePeriod  time.Duration `mapstructure:"grace_period" json:"grace_period" validate:"gtefield=DrainTimeout"`
+	DrainTimeout time.Duration `mapstructure:"drain_timeout" json:"drain_timeout" validate:"gte=0"`
 }
 
 type DatabaseConfig struct {
 	Host     string `mapstructure:"host" json:"host"`
 	Port     int    `mapstructure:"port" json:"port"`
 	Username string `mapstructure:"username" json:"username"`
-	Password string `mapstructure:"password" json:"password"`
-	Name     string `mapstructure:"name" json:"name"`
+	Password string `mapstructure:"password" json:"password" secret:"true"`
+	// PasswordFile is read into Password at load time and takes precedence over it
+	PasswordFile string `mapstructure:"password_file" json:"password_file" validate:"omitempty,file"`
+	Name         string `mapstructure:"name" json:"name"`
 }
 
 type LoggingConfig struct {
 	Level  string `mapstructure:"level" json:"level"`
 	Format string `mapstructure:"format" json:"format"`
 }
+
+type AuditConfig struct {
+	Enabled bool   `mapstructure:"enabled" json:"enabled"`
+	Sink    string `mapstructure:"sink" json:"sink" validate:"omitempty,oneof=stderr file"`
+	Path    string `mapstructure:"path" json:"path" validate:"required_if=Sink file"`
+}
+
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled" json:"enabled"`
+	Listen  string `mapstructure:"listen" json:"listen" validate:"required_if=Enabled true,omitempty,hostname_port"`
+}
+
+// AdminConfig enables the admin listener in serve mode, which exposes the
+// running configuration with secrets redacted
+type AdminConfig struct {
+	Enabled bool   `mapstructure:"enabled" json:"enabled"`
+	Listen  string `mapstructure:"listen" json:"listen" validate:"required_if=Enabled true,omitempty,hostname_port"`
+}
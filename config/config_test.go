@@ -0,0 +1,228 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func sampleConfig() *Config {
+	return &Config{
+		App:      AppConfig{Name: "app", Version: "1.0.0", Environment: "production"},
+		Server:   ServerConfig{Host: "localhost", Port: 8080, Timeout: 30},
+		Database: DatabaseConfig{Host: "db.local", Port: 5432, Username: "user", Password: "secret", Name: "dbname"},
+		Logging:  LoggingConfig{Level: "info", Format: "json"},
+		Extensions: map[string]any{
+			"cache": map[string]any{
+				"ttl":   60,
+				"nodes": []any{"a", "b", map[string]any{"weight": 2}},
+			},
+			"tags": []string{"x", "y"},
+		},
+	}
+}
+
+func TestCloneIsEqual(t *testing.T) {
+	orig := sampleConfig()
+	clone := orig.Clone()
+
+	if clone == orig {
+		t.Fatal("Clone returned the same pointer")
+	}
+	if !reflect.DeepEqual(orig, clone) {
+		t.Errorf("Clone differs from original:\n got %#v\nwant %#v", clone, orig)
+	}
+}
+
+func TestCloneDoesNotAliasNestedMaps(t *testing.T) {
+	orig := sampleConfig()
+	clone := orig.Clone()
+
+	cache := clone.Extensions["cache"].(map[string]any)
+	cache["ttl"] = 120
+	cache["nodes"].([]any)[2].(map[string]any)["weight"] = 5
+	clone.Extensions["new"] = true
+
+	origCache := orig.Extensions["cache"].(map[string]any)
+	if origCache["ttl"] != 60 {
+		t.Errorf("Expected original ttl=60, got %v", origCache["ttl"])
+	}
+	if w := origCache["nodes"].([]any)[2].(map[string]any)["weight"]; w != 2 {
+		t.Errorf("Expected original nested weight=2, got %v", w)
+	}
+	if _, ok := orig.Extensions["new"]; ok {
+		t.Error("Key added to clone leaked into original Extensions")
+	}
+}
+
+func TestCloneDoesNotAliasSlices(t *testing.T) {
+	orig := sampleConfig()
+	clone := orig.Clone()
+
+	clone.Extensions["cache"].(map[string]any)["nodes"].([]any)[0] = "changed"
+	clone.Extensions["tags"].([]string)[1] = "changed"
+
+	if n := orig.Extensions["cache"].(map[string]any)["nodes"].([]any)[0]; n != "a" {
+		t.Errorf("Expected original nodes[0]=a, got %v", n)
+	}
+	if tag := orig.Extensions["tags"].([]string)[1]; tag != "y" {
+		t.Errorf("Expected original tags[1]=y, got %v", tag)
+	}
+}
+
+func TestCloneScalarFields(t *testing.T) {
+	orig := sampleConfig()
+	clone := orig.Clone()
+	clone.Server.Port = 9000
+	clone.Database.Password = "other"
+
+	if orig.Server.Port != 8080 {
+		t.Errorf("Expected original Server.Port=8080, got %d", orig.Server.Port)
+	}
+	if orig.Database.Password != "secret" {
+		t.Errorf("Expected original Database.Password=secret, got %s", orig.Database.Password)
+	}
+}
+
+func TestCloneNil(t *testing.T) {
+	var c *Config
+	if c.Clone() != nil {
+		t.Error("Expected Clone of nil config to return nil")
+	}
+
+	empty := &Config{}
+	if clone := empty.Clone(); clone.Extensions != nil {
+		t.Errorf("Expected nil Extensions to stay nil, got %#v", clone.Extensions)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a, b := sampleConfig(), sampleConfig()
+	if !a.Equal(b) {
+		t.Error("Expected identical configs to be equal")
+	}
+
+	b.Extensions["cache"].(map[string]any)["ttl"] = 61
+	if a.Equal(b) {
+		t.Error("Expected configs with different extension values to differ")
+	}
+
+	var nilCfg *Config
+	if nilCfg.Equal(a) || !nilCfg.Equal(nil) {
+		t.Error("Unexpected Equal result for nil configs")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldCfg, newCfg := sampleConfig(), sampleConfig()
+	newCfg.Server.Port = 9000
+	newCfg.Database.Password = "rotated"
+	newCfg.Extensions["cache"].(map[string]any)["ttl"] = 120
+	delete(newCfg.Extensions, "tags")
+	newCfg.Extensions["feature"] = "on"
+
+	want := []Change{
+		{Key: "server.port", Old: 8080, New: 9000},
+		{Key: "database.password", Old: Redacted, New: Redacted},
+		{Key: "extensions.cache.ttl", Old: 60, New: 120},
+		{Key: "extensions.feature", Old: nil, New: "on"},
+		{Key: "extensions.tags", Old: []string{"x", "y"}, New: nil},
+	}
+	if got := oldCfg.Diff(newCfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected diff:\n got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestDiffRedactsSecretWhenUnset(t *testing.T) {
+	oldCfg, newCfg := sampleConfig(), sampleConfig()
+	newCfg.Database.Password = ""
+
+	got := oldCfg.Diff(newCfg)
+	want := []Change{{Key: "database.password", Old: Redacted, New: ""}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected diff:\n got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestDiffTypedNestedMaps(t *testing.T) {
+	oldCfg, newCfg := sampleConfig(), sampleConfig()
+	oldCfg.Extensions["pools"] = map[string]map[string]any{"primary": {"size": 4}}
+	newCfg.Extensions["pools"] = map[string]map[string]any{"primary": {"size": 8}}
+
+	got := oldCfg.Diff(newCfg)
+	want := []Change{{Key: "extensions.pools.primary.size", Old: 4, New: 8}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected diff:\n got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestRewriteStrings(t *testing.T) {
+	cfg := sampleConfig()
+	var keys []string
+	err := cfg.RewriteStrings(func(key, value string) (string, error) {
+		keys = append(keys, key)
+		if key == "database.password" || key == "extensions.cache.nodes" {
+			return value + "!", nil
+		}
+		return value, nil
+	})
+	if err != nil {
+		t.Fatalf("RewriteStrings failed: %v", err)
+	}
+
+	if cfg.Database.Password != "secret!" {
+		t.Errorf("Expected rewritten password, got %q", cfg.Database.Password)
+	}
+	nodes := cfg.Extensions["cache"].(map[string]any)["nodes"].([]any)
+	if nodes[0] != "a!" || nodes[1] != "b!" {
+		t.Errorf("Expected rewritten extension strings, got %v", nodes)
+	}
+	if cfg.App.Name != "app" {
+		t.Errorf("Expected untouched app.name, got %q", cfg.App.Name)
+	}
+	for _, key := range keys {
+		if key == "server.port" {
+			t.Error("Non-string setting passed to rewrite function")
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c := sampleConfig()
+	tests := []struct {
+		key  string
+		want any
+	}{
+		{"server.port", 8080},
+		{"database.host", "db.local"},
+		{"logging", LoggingConfig{Level: "info", Format: "json"}},
+		{"extensions.cache.ttl", 60},
+	}
+	for _, tt := range tests {
+		got, ok := c.Lookup(tt.key)
+		if !ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lookup(%q) = %v, %v, want %v", tt.key, got, ok, tt.want)
+		}
+	}
+	for _, key := range []string{"", "server.nope", "server.port.x", "extensions.missing"} {
+		if got, ok := c.Lookup(key); ok {
+			t.Errorf("Lookup(%q) = %v, want no value", key, got)
+		}
+	}
+}
+
+func TestDeprecatedTag(t *testing.T) {
+	type section struct {
+		Timeout int `mapstructure:"timeout" deprecated:"use server.shutdown.grace_period instead"`
+		Port    int `mapstructure:"port"`
+	}
+	var settings []Setting
+	collectSettings("server", reflect.ValueOf(section{}), &settings)
+	if len(settings) != 2 || settings[0].Deprecated != "use server.shutdown.grace_period instead" || settings[1].Deprecated != "" {
+		t.Errorf("Expected only server.timeout to carry the deprecation notice, got %+v", settings)
+	}
+
+	timeout := structSchema(reflect.TypeOf(section{}))["properties"].(map[string]any)["timeout"].(map[string]any)
+	if timeout["deprecated"] != true {
+		t.Errorf("Expected the schema to mark server.timeout deprecated, got %v", timeout)
+	}
+}
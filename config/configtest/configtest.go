@@ -0,0 +1,422 @@
+// Package configtest generates random configurations for property-based tests.
+// Values are driven by the validate tags of config.Config, so new fields and
+// constraints are covered without touching the generator.
+package configtest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/cobra-viper-demo/codec"
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/go-playground/validator/v10"
+)
+
+// maxAttempts bounds the retries when a generated configuration trips a
+// constraint the generator does not model, such as a cross-section rule
+const maxAttempts = 100
+
+// Generator produces random configurations. It is not safe for concurrent use.
+// Fields validated against the file system (file, dir) are left empty, since
+// the files would have to exist.
+type Generator struct {
+	r        *rand.Rand
+	validate *validator.Validate
+}
+
+// New returns a generator; the same seed yields the same sequence of configurations
+func New(seed uint64) *Generator {
+	return &Generator{r: rand.New(rand.NewPCG(seed, seed)), validate: config.NewValidator()}
+}
+
+// Valid returns a random configuration that passes validation, including the
+// cross-section rules
+func (g *Generator) Valid() *config.Config {
+	for range maxAttempts {
+		var cfg config.Config
+		g.fill(reflect.ValueOf(&cfg).Elem())
+		if g.validate.Struct(&cfg) == nil {
+			return &cfg
+		}
+	}
+	panic("configtest: no valid configuration generated in " + strconv.Itoa(maxAttempts) + " attempts")
+}
+
+// Invalid returns a random configuration that fails validation, with exactly one
+// field tag constraint broken, and the dotted key of that field
+func (g *Generator) Invalid() (*config.Config, string) {
+	var breakers []breaker
+	for _, field := range config.Fields() {
+		for _, tag := range parseTags(field.Validate) {
+			if b, ok := breakerFor(field.Key, tag); ok {
+				breakers = append(breakers, b)
+			}
+		}
+	}
+	for range maxAttempts {
+		cfg := g.Valid()
+		b := breakers[g.r.IntN(len(breakers))]
+		if !b.apply(g, reflect.ValueOf(cfg).Elem()) {
+			continue
+		}
+		if failsOn(g.validate.Struct(cfg), b.key) {
+			return cfg, b.key
+		}
+	}
+	panic("configtest: no invalid configuration generated in " + strconv.Itoa(maxAttempts) + " attempts")
+}
+
+// failsOn reports whether err holds a validation error for key
+func failsOn(err error, key string) bool {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return false
+	}
+	for _, fieldErr := range validationErrors {
+		if config.KeyForNamespace(fieldErr.Namespace()) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// tag is a single validate tag such as gte=1024
+type tag struct {
+	name  string
+	param string
+}
+
+func parseTags(validate string) []tag {
+	var tags []tag
+	for _, t := range strings.Split(validate, ",") {
+		if t == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(t, "=")
+		tags = append(tags, tag{name, param})
+	}
+	return tags
+}
+
+// fill sets every field of the struct v from its validate tags. Cross-field
+// tags such as gtefield are applied once the other fields have values.
+func (g *Generator) fill(v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		field := v.Field(i)
+		if sf.Type.Kind() == reflect.Struct {
+			g.fill(field)
+			continue
+		}
+		g.fillField(field, parseTags(sf.Tag.Get("validate")))
+	}
+	for i := range t.NumField() {
+		for _, tg := range parseTags(t.Field(i).Tag.Get("validate")) {
+			if !strings.HasSuffix(tg.name, "field") {
+				continue
+			}
+			if other := v.FieldByName(tg.param); other.IsValid() {
+				applyFieldComparison(g.r, v.Field(i), other, tg.name)
+			}
+		}
+	}
+}
+
+func (g *Generator) fillField(field reflect.Value, tags []tag) {
+	var lo, hi *int64
+	optional := false
+	for _, tg := range tags {
+		switch tg.name {
+		case "omitempty":
+			optional = true
+		case "required_if", "required_with", "required_unless", "required":
+			optional = false
+		case "file", "dir", "filepath", "dirpath":
+			// Would need the file to exist
+			return
+		case "gte", "min":
+			n, _ := strconv.ParseInt(tg.param, 10, 64)
+			lo = &n
+		case "lte", "max":
+			n, _ := strconv.ParseInt(tg.param, 10, 64)
+			hi = &n
+		}
+	}
+	if optional && g.r.IntN(4) == 0 {
+		return
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(g.stringFor(tags))
+	case reflect.Bool:
+		field.SetBool(g.r.IntN(2) == 0)
+	case reflect.Int, reflect.Int64:
+		if field.Type() == reflect.TypeOf(time.Duration(0)) {
+			field.SetInt(int64(time.Duration(g.r.IntN(120)) * time.Second))
+			return
+		}
+		min, max := int64(0), int64(10000)
+		if lo != nil {
+			min = *lo
+		}
+		if hi != nil {
+			max = *hi
+		} else if lo != nil {
+			max = min + 10000
+		}
+		field.SetInt(min + g.r.Int64N(max-min+1))
+	}
+}
+
+// stringFor generates a string satisfying the string tags
+func (g *Generator) stringFor(tags []tag) string {
+	for _, tg := range tags {
+		switch tg.name {
+		case "oneof":
+			options := strings.Fields(tg.param)
+			return options[g.r.IntN(len(options))]
+		case "hostname_port":
+			hosts := []string{"", "localhost", "127.0.0.1", "example.com"}
+			return hosts[g.r.IntN(len(hosts))] + ":" + strconv.Itoa(1024+g.r.IntN(64000))
+		case "pattern":
+			if p, ok := config.LookupPattern(tg.param); ok {
+				for range maxAttempts {
+					if s, err := generateMatching(g.r, p.Expr); err == nil && s != "" && p.Match(s) {
+						return s
+					}
+				}
+			}
+		}
+	}
+	return g.word()
+}
+
+// word returns a short random lowercase word
+func (g *Generator) word() string {
+	b := make([]byte, 3+g.r.IntN(10))
+	for i := range b {
+		b[i] = byte('a' + g.r.IntN(26))
+	}
+	return string(b)
+}
+
+// applyFieldComparison adjusts field so that it satisfies a cross-field tag
+// relative to other, a field of the same struct
+func applyFieldComparison(r *rand.Rand, field, other reflect.Value, name string) {
+	if !field.CanInt() || !other.CanInt() {
+		return
+	}
+	step := int64(1 + r.IntN(100))
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		step *= int64(time.Second)
+	}
+	switch name {
+	case "gtefield", "gtfield":
+		field.SetInt(other.Int() + step)
+	case "ltefield", "ltfield":
+		field.SetInt(other.Int() - step)
+	}
+}
+
+// breaker breaks one tag constraint of the field at key
+type breaker struct {
+	key   string
+	apply func(g *Generator, cfg reflect.Value) bool
+}
+
+// breakerFor returns a way to violate tag on the field at key, when there is one
+func breakerFor(key string, tg tag) (breaker, bool) {
+	set := func(fn func(g *Generator, field reflect.Value) bool) breaker {
+		return breaker{key: key, apply: func(g *Generator, cfg reflect.Value) bool {
+			return fn(g, fieldAt(cfg, key))
+		}}
+	}
+	switch tg.name {
+	case "required":
+		return set(func(_ *Generator, field reflect.Value) bool {
+			field.SetZero()
+			return true
+		}), true
+	case "oneof":
+		return set(func(g *Generator, field reflect.Value) bool {
+			field.SetString("not-" + g.word())
+			return true
+		}), true
+	case "hostname_port":
+		return set(func(g *Generator, field reflect.Value) bool {
+			field.SetString(g.word() + " " + g.word())
+			return true
+		}), true
+	case "pattern":
+		return set(func(g *Generator, field reflect.Value) bool {
+			field.SetString("0 " + g.word() + "!")
+			return true
+		}), true
+	case "file":
+		return set(func(g *Generator, field reflect.Value) bool {
+			field.SetString("/nonexistent/" + g.word())
+			return true
+		}), true
+	case "gte", "min", "lte", "max":
+		return set(func(g *Generator, field reflect.Value) bool {
+			if !field.CanInt() {
+				return false
+			}
+			n, err := strconv.ParseInt(tg.param, 10, 64)
+			if err != nil {
+				return false
+			}
+			if tg.name == "gte" || tg.name == "min" {
+				field.SetInt(n - 1 - g.r.Int64N(100))
+			} else {
+				field.SetInt(n + 1 + g.r.Int64N(100))
+			}
+			return true
+		}), true
+	case "gtefield", "gtfield":
+		return breaker{key: key, apply: func(g *Generator, cfg reflect.Value) bool {
+			field := fieldAt(cfg, key)
+			parent := fieldAt(cfg, parentKey(key))
+			if !parent.IsValid() {
+				parent = cfg
+			}
+			other := parent.FieldByName(tg.param)
+			if !other.IsValid() || !field.CanInt() {
+				return false
+			}
+			applyFieldComparison(g.r, field, other, "ltfield")
+			return true
+		}}, true
+	case "required_if":
+		// required_if=Sink file: set the condition and leave the field empty
+		cond, value, ok := strings.Cut(tg.param, " ")
+		if !ok {
+			return breaker{}, false
+		}
+		return breaker{key: key, apply: func(g *Generator, cfg reflect.Value) bool {
+			parent := fieldAt(cfg, parentKey(key))
+			if !parent.IsValid() {
+				parent = cfg
+			}
+			other := parent.FieldByName(cond)
+			if !other.IsValid() || !setFromString(other, value) {
+				return false
+			}
+			fieldAt(cfg, key).SetZero()
+			return true
+		}}, true
+	}
+	return breaker{}, false
+}
+
+// setFromString sets a string or bool field from a tag parameter
+func setFromString(field reflect.Value, value string) bool {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		field.SetBool(b)
+	default:
+		return false
+	}
+	return true
+}
+
+// parentKey returns the key of the struct holding key, empty at the top level
+func parentKey(key string) string {
+	i := strings.LastIndex(key, ".")
+	if i < 0 {
+		return ""
+	}
+	return key[:i]
+}
+
+// fieldAt returns the field of the struct v at a dotted key
+func fieldAt(v reflect.Value, key string) reflect.Value {
+	if key == "" {
+		return reflect.Value{}
+	}
+	for _, part := range strings.Split(key, ".") {
+		t := v.Type()
+		found := false
+		for i := range t.NumField() {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ",")
+			if name == part {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}
+		}
+	}
+	return v
+}
+
+// codecs are the formats Marshal writes
+var codecs = map[string]codec.Codec{
+	"yaml":       codec.YAML{},
+	"json":       codec.JSON{},
+	"toml":       codec.TOML{},
+	"properties": codec.Properties{},
+	"ini":        codec.INI{},
+}
+
+// Formats lists the formats Marshal supports
+func Formats() []string {
+	formats := make([]string, 0, len(codecs))
+	for format := range codecs {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// Marshal serializes cfg as a config file in format, as an operator would write
+// it: durations as strings such as "30s" and unset fields omitted
+func Marshal(cfg *config.Config, format string) ([]byte, error) {
+	c, ok := codecs[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	settings := map[string]any{}
+	for _, s := range cfg.Settings() {
+		value := reflect.ValueOf(s.Value)
+		if value.IsZero() {
+			continue
+		}
+		if d, ok := s.Value.(time.Duration); ok {
+			s.Value = d.String()
+		}
+		parts := strings.Split(s.Key, ".")
+		m := settings
+		for _, part := range parts[:len(parts)-1] {
+			nested, ok := m[part].(map[string]any)
+			if !ok {
+				nested = map[string]any{}
+				m[part] = nested
+			}
+			m = nested
+		}
+		m[parts[len(parts)-1]] = s.Value
+	}
+	if len(cfg.Extensions) > 0 {
+		settings["extensions"] = cfg.Extensions
+	}
+	return c.Encode(settings)
+}
@@ -0,0 +1,82 @@
+package configtest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/example/cobra-viper-demo/codec"
+	"github.com/example/cobra-viper-demo/config"
+	"github.com/spf13/viper"
+)
+
+func TestValidIsDeterministic(t *testing.T) {
+	a, b := New(42).Valid(), New(42).Valid()
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Expected the same configuration for the same seed:\n%+v\n%+v", a, b)
+	}
+}
+
+func TestValid(t *testing.T) {
+	g := New(1)
+	validate := config.NewValidator()
+	for range 200 {
+		cfg := g.Valid()
+		if err := validate.Struct(cfg); err != nil {
+			t.Fatalf("Generated configuration is invalid: %v\n%+v", err, cfg)
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	g := New(2)
+	validate := config.NewValidator()
+	broken := map[string]bool{}
+	for range 200 {
+		cfg, key := g.Invalid()
+		if err := validate.Struct(cfg); !failsOn(err, key) {
+			t.Fatalf("Expected %s to fail validation, got %v", key, err)
+		}
+		broken[key] = true
+	}
+	for _, key := range []string{"app.name", "server.port", "app.environment", "audit.path", "metrics.listen"} {
+		if !broken[key] {
+			t.Errorf("Expected %s to be broken at least once, got %v", key, broken)
+		}
+	}
+}
+
+// TestMarshalRoundTrip checks that every generated configuration survives being
+// written in each format and loaded back through viper
+func TestMarshalRoundTrip(t *testing.T) {
+	registry := viper.NewCodecRegistry()
+	registry.RegisterCodec("yaml", codec.YAML{})
+	registry.RegisterCodec("json", codec.JSON{})
+	registry.RegisterCodec("toml", codec.TOML{})
+	registry.RegisterCodec("properties", codec.Properties{})
+	registry.RegisterCodec("ini", codec.INI{})
+
+	g := New(3)
+	for _, format := range Formats() {
+		for range 50 {
+			cfg := g.Valid()
+			data, err := Marshal(cfg, format)
+			if err != nil {
+				t.Fatalf("Marshal(%s) failed: %v", format, err)
+			}
+
+			v := viper.NewWithOptions(viper.WithCodecRegistry(registry))
+			v.SetConfigType(format)
+			if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+				t.Fatalf("Reading %s failed: %v\n%s", format, err, data)
+			}
+			var loaded config.Config
+			if err := v.UnmarshalExact(&loaded); err != nil {
+				t.Fatalf("Unmarshaling %s failed: %v\n%s", format, err, data)
+			}
+			if diff := cfg.Diff(&loaded); len(diff) > 0 {
+				t.Fatalf("Round trip through %s changed %+v\n%s", format, diff, data)
+			}
+		}
+	}
+}
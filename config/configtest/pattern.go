@@ -0,0 +1,80 @@
+package configtest
+
+import (
+	"math/rand/v2"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxRepeat bounds unbounded repetitions such as * and + in generated strings
+const maxRepeat = 8
+
+// generateMatching returns a random string for the regular expression. Anchors
+// and word boundaries are not modelled, so callers check the result against the
+// pattern.
+func generateMatching(r *rand.Rand, expr string) (string, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	writeMatching(r, re.Simplify(), &b)
+	return b.String(), nil
+}
+
+func writeMatching(r *rand.Rand, re *syntax.Regexp, b *strings.Builder) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(randomInClass(r, re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		b.WriteRune(rune('a' + r.IntN(26)))
+	case syntax.OpCapture:
+		writeMatching(r, re.Sub[0], b)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeMatching(r, sub, b)
+		}
+	case syntax.OpAlternate:
+		writeMatching(r, re.Sub[r.IntN(len(re.Sub))], b)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			lo, hi = 0, -1
+		case syntax.OpPlus:
+			lo, hi = 1, -1
+		case syntax.OpQuest:
+			lo, hi = 0, 1
+		}
+		if hi < 0 || hi > lo+maxRepeat {
+			hi = lo + maxRepeat
+		}
+		for n := lo + r.IntN(hi-lo+1); n > 0; n-- {
+			writeMatching(r, re.Sub[0], b)
+		}
+	}
+	// Anchors, word boundaries, and empty matches produce no text
+}
+
+// randomInClass picks a rune from a character class given as [lo, hi] pairs,
+// preferring printable ASCII so generated values survive every file format
+func randomInClass(r *rand.Rand, ranges []rune) rune {
+	var ascii []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], min(ranges[i+1], '~')
+		if lo < ' ' {
+			lo = ' '
+		}
+		if lo <= hi {
+			ascii = append(ascii, lo, hi)
+		}
+	}
+	if len(ascii) == 0 {
+		ascii = ranges
+	}
+	pair := r.IntN(len(ascii)/2) * 2
+	lo, hi := ascii[pair], ascii[pair+1]
+	return lo + rune(r.IntN(int(hi-lo)+1))
+}
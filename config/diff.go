@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Redacted replaces secret values wherever configuration is reported back to users
+const Redacted = "[REDACTED]"
+
+// Change describes a single differing configuration key between two configs
+type Change struct {
+	Key string `json:"key"`
+	Old any    `json:"old"`
+	New any    `json:"new"`
+}
+
+// Equal reports whether both configurations hold the same values
+func (c *Config) Equal(other *Config) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return len(c.Diff(other)) == 0
+}
+
+// Diff lists the keys whose values differ between c (old) and other (new), using
+// dotted mapstructure key paths. Secret fields are reported with redacted values.
+func (c *Config) Diff(other *Config) []Change {
+	if c == nil {
+		c = &Config{}
+	}
+	if other == nil {
+		other = &Config{}
+	}
+	var changes []Change
+	diffStruct("", reflect.ValueOf(*c), reflect.ValueOf(*other), &changes)
+	return changes
+}
+
+func diffStruct(prefix string, oldVal, newVal reflect.Value, changes *[]Change) {
+	t := oldVal.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := joinKey(prefix, fieldKey(sf))
+		o, n := oldVal.Field(i), newVal.Field(i)
+
+		switch {
+		case sf.Type.Kind() == reflect.Struct:
+			diffStruct(key, o, n, changes)
+		case sf.Type.Kind() == reflect.Map:
+			diffMap(key, o, n, changes)
+		case !reflect.DeepEqual(o.Interface(), n.Interface()):
+			change := Change{Key: key, Old: o.Interface(), New: n.Interface()}
+			if isSecret(sf) {
+				change.Old, change.New = redact(o), redact(n)
+			}
+			*changes = append(*changes, change)
+		}
+	}
+}
+
+func diffMap(prefix string, oldVal, newVal reflect.Value, changes *[]Change) {
+	keys := map[string]reflect.Value{}
+	for _, m := range []reflect.Value{oldVal, newVal} {
+		if m.IsNil() {
+			continue
+		}
+		for _, k := range m.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := joinKey(prefix, name)
+		o, n := mapValue(oldVal, keys[name]), mapValue(newVal, keys[name])
+		if isMap(o) && isMap(n) {
+			diffMap(key, unwrap(o), unwrap(n), changes)
+			continue
+		}
+		var oldIface, newIface any
+		if o.IsValid() {
+			oldIface = o.Interface()
+		}
+		if n.IsValid() {
+			newIface = n.Interface()
+		}
+		if !reflect.DeepEqual(oldIface, newIface) {
+			*changes = append(*changes, Change{Key: key, Old: oldIface, New: newIface})
+		}
+	}
+}
+
+// mapValue looks up key in m, returning the zero Value when absent
+func mapValue(m, key reflect.Value) reflect.Value {
+	if m.IsNil() {
+		return reflect.Value{}
+	}
+	return m.MapIndex(key)
+}
+
+// isMap reports whether v holds a map, possibly wrapped in an interface
+func isMap(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+	v = unwrap(v)
+	return v.Kind() == reflect.Map && !v.IsNil()
+}
+
+// unwrap returns the value held by an interface, or v itself when it is not one;
+// values of typed maps such as map[string]map[string]any are not wrapped
+func unwrap(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		return v.Elem()
+	}
+	return v
+}
+
+// redact masks non-empty secret values so callers can still tell set from unset
+func redact(v reflect.Value) any {
+	if v.IsZero() {
+		return v.Interface()
+	}
+	return Redacted
+}
+
+// fieldKey returns the mapstructure key of a struct field
+func fieldKey(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("mapstructure"); tag != "" {
+		return tag
+	}
+	return sf.Name
+}
+
+// isSecret reports whether a struct field is tagged as holding a secret
+func isSecret(sf reflect.StructField) bool {
+	return sf.Tag.Get("secret") == "true"
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-viper/mapstructure/v2"
+)
+
+var (
+	extensionsMu      sync.RWMutex
+	extensionSchemas  = map[string]reflect.Type{}
+	errNotStructValue = errors.New("extension schema must be a struct or pointer to struct")
+)
+
+// RegisterExtension registers the schema of the extension stored under
+// extensions.<name>. The schema is a struct (or pointer to one) whose
+// mapstructure and validate tags describe the extension's settings, e.g.
+//
+//	config.RegisterExtension("cache", CacheConfig{})
+//
+// Extensions without a registered schema are accepted as free-form values.
+func RegisterExtension(name string, schema any) error {
+	t := reflect.TypeOf(schema)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("registering extension %q: %w", name, errNotStructValue)
+	}
+
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+	if _, ok := extensionSchemas[name]; ok {
+		return fmt.Errorf("extension %q is already registered", name)
+	}
+	extensionSchemas[name] = t
+	return nil
+}
+
+// ExtensionError reports an extension that does not match its registered schema
+type ExtensionError struct {
+	Name   string
+	Err    error
+	schema reflect.Type
+}
+
+func (e *ExtensionError) Error() string {
+	return fmt.Sprintf("extension %q: %v", e.Name, e.Err)
+}
+
+func (e *ExtensionError) Unwrap() error {
+	return e.Err
+}
+
+// Key converts a validator namespace within the extension schema into the
+// dotted viper key, e.g. "CacheConfig.TTL" into "extensions.cache.ttl"
+func (e *ExtensionError) Key(namespace string) string {
+	key := "extensions." + e.Name
+	if rest := keyForNamespace(e.schema, namespace); rest != "" {
+		key += "." + rest
+	}
+	return key
+}
+
+// ValidateExtensions decodes every extension with a registered schema into that
+// schema, rejecting unknown keys, and validates it. It stops at the first
+// invalid extension, in name order, returning an *ExtensionError.
+func ValidateExtensions(extensions map[string]any, validate *validator.Validate) error {
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		extensionsMu.RLock()
+		schema, ok := extensionSchemas[name]
+		extensionsMu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		target := reflect.New(schema)
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			ErrorUnused:      true,
+			WeaklyTypedInput: true,
+			Result:           target.Interface(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := decoder.Decode(extensions[name]); err != nil {
+			return &ExtensionError{Name: name, Err: err, schema: schema}
+		}
+		if err := validate.Struct(target.Interface()); err != nil {
+			return &ExtensionError{Name: name, Err: err, schema: schema}
+		}
+	}
+	return nil
+}
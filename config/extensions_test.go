@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type cacheExtension struct {
+	TTL   time.Duration `mapstructure:"ttl" validate:"gt=0"`
+	Nodes []string      `mapstructure:"nodes" validate:"min=1"`
+}
+
+func TestValidateExtensions(t *testing.T) {
+	name := "test-cache"
+	if err := RegisterExtension(name, &cacheExtension{}); err != nil {
+		t.Fatalf("RegisterExtension failed: %v", err)
+	}
+	defer func() {
+		extensionsMu.Lock()
+		delete(extensionSchemas, name)
+		extensionsMu.Unlock()
+	}()
+
+	if err := RegisterExtension(name, cacheExtension{}); err == nil {
+		t.Error("Expected error registering the same extension twice")
+	}
+	if err := RegisterExtension("bad", "not a struct"); err == nil {
+		t.Error("Expected error registering a non-struct schema")
+	}
+
+	validate := validator.New()
+	tests := []struct {
+		name    string
+		ext     any
+		wantErr bool
+		wantKey string
+	}{
+		{name: "Valid", ext: map[string]any{"ttl": "30s", "nodes": []any{"a"}}},
+		{name: "Unknown Key", ext: map[string]any{"ttl": "30s", "nodes": []any{"a"}, "extra": 1}, wantErr: true},
+		{name: "Invalid Value", ext: map[string]any{"ttl": "0s", "nodes": []any{"a"}}, wantErr: true, wantKey: "extensions.test-cache.ttl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exts := map[string]any{name: tt.ext, "unregistered": map[string]any{"anything": true}}
+			err := ValidateExtensions(exts, validate)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+			if tt.wantKey == "" {
+				return
+			}
+			var extErr *ExtensionError
+			var validationErrors validator.ValidationErrors
+			if !errors.As(err, &extErr) || !errors.As(err, &validationErrors) {
+				t.Fatalf("Expected ExtensionError wrapping ValidationErrors, got %T", err)
+			}
+			if key := extErr.Key(validationErrors[0].StructNamespace()); key != tt.wantKey {
+				t.Errorf("Expected key %s, got %s", tt.wantKey, key)
+			}
+		})
+	}
+}
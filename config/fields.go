@@ -0,0 +1,158 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Field describes a single leaf configuration key derived from the Config struct
+type Field struct {
+	Key      string       // dotted viper key, e.g. "server.port"
+	Type     reflect.Type // Go type of the field
+	Validate string       // raw validate tag
+	Secret   bool         // tagged secret:"true"
+
+	// Deprecated holds the deprecation notice from the deprecated tag, e.g.
+	// deprecated:"use server.shutdown.grace_period instead"; empty when current
+	Deprecated string
+}
+
+// TypeName returns a short, user-facing name for the field type
+func (f Field) TypeName() string {
+	if f.Type == reflect.TypeOf(time.Duration(0)) {
+		return "duration"
+	}
+	return f.Type.Kind().String()
+}
+
+// Fields returns every leaf key of Config in declaration order. Free-form maps
+// such as Extensions are not part of the catalog.
+func Fields() []Field {
+	settings := (&Config{}).Settings()
+	fields := make([]Field, len(settings))
+	for i, s := range settings {
+		fields[i] = s.Field
+	}
+	return fields
+}
+
+// Constraints summarizes the validate tag in human-readable form
+func (f Field) Constraints() string {
+	var parts []string
+	for _, rule := range strings.Split(f.Validate, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "", "omitempty":
+			continue
+		case "required":
+			parts = append(parts, "required")
+		case "oneof":
+			parts = append(parts, "one of "+strings.Join(strings.Fields(param), "|"))
+		case "gte", "min":
+			parts = append(parts, ">= "+param)
+		case "lte", "max":
+			parts = append(parts, "<= "+param)
+		case "gt":
+			parts = append(parts, "> "+param)
+		case "lt":
+			parts = append(parts, "< "+param)
+		case "eq":
+			parts = append(parts, "= "+param)
+		case "gtefield":
+			parts = append(parts, ">= "+param)
+		case "gtfield":
+			parts = append(parts, "> "+param)
+		case "ltefield":
+			parts = append(parts, "<= "+param)
+		case "ltfield":
+			parts = append(parts, "< "+param)
+		case "required_with":
+			parts = append(parts, "required with "+param)
+		case "ne":
+			parts = append(parts, "!= "+param)
+		case "len":
+			parts = append(parts, "length "+param)
+		case "pattern":
+			parts = append(parts, "matches "+param)
+		default:
+			if param != "" {
+				parts = append(parts, name+"="+param)
+			} else {
+				parts = append(parts, name)
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Setting pairs a catalog field with its value in a particular Config
+type Setting struct {
+	Field
+	Value any
+}
+
+// Settings returns the value of every catalog field of c, in declaration order
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	collectSettings("", reflect.ValueOf(*c), &settings)
+	return settings
+}
+
+func collectSettings(prefix string, v reflect.Value, settings *[]Setting) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := joinKey(prefix, fieldKey(sf))
+		switch sf.Type.Kind() {
+		case reflect.Struct:
+			collectSettings(key, v.Field(i), settings)
+		case reflect.Map:
+			continue
+		default:
+			*settings = append(*settings, Setting{
+				Field: Field{
+					Key:        key,
+					Type:       sf.Type,
+					Validate:   sf.Tag.Get("validate"),
+					Secret:     isSecret(sf),
+					Deprecated: sf.Tag.Get("deprecated"),
+				},
+				Value: v.Field(i).Interface(),
+			})
+		}
+	}
+}
+
+// KeyForNamespace converts a validator struct namespace such as
+// "Config.Server.Port" into the dotted viper key "server.port". Unknown names
+// are lowercased so the result stays readable.
+func KeyForNamespace(namespace string) string {
+	return keyForNamespace(reflect.TypeOf(Config{}), namespace)
+}
+
+// keyForNamespace maps a struct namespace rooted at type t onto mapstructure keys
+func keyForNamespace(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 0 && parts[0] == t.Name() {
+		parts = parts[1:]
+	}
+	keys := make([]string, 0, len(parts))
+	for _, name := range parts {
+		if t.Kind() != reflect.Struct {
+			keys = append(keys, strings.ToLower(name))
+			continue
+		}
+		sf, ok := t.FieldByName(name)
+		if !ok {
+			keys = append(keys, strings.ToLower(name))
+			continue
+		}
+		keys = append(keys, fieldKey(sf))
+		t = sf.Type
+	}
+	return strings.Join(keys, ".")
+}
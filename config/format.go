@@ -0,0 +1,150 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// FormatYAML re-emits a YAML config document in canonical form: sections and keys
+// in struct declaration order, with unknown keys after the known ones in their
+// original order, extensions sorted by name, and two-space indentation. Comments
+// stay attached to their keys.
+//
+// drop is called with the dotted key and scalar value of every known leaf; keys
+// it returns true for are removed, and so are sections left empty. It may be nil.
+func FormatYAML(data []byte, drop func(key, value string) bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		return data, nil
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("config document must be a YAML mapping")
+	}
+	root := doc.Content[0]
+	// A comment above the first key is the file header; keep it at the top
+	if doc.HeadComment == "" && len(root.Content) > 0 {
+		doc.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	formatMapping(root, reflect.TypeOf(Config{}), "", drop)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return separateSections(buf.Bytes()), nil
+}
+
+// separateSections puts a blank line before every top-level section after the
+// first, including the comments directly above it
+func separateSections(out []byte) []byte {
+	lines := strings.SplitAfter(string(out), "\n")
+	var b strings.Builder
+	seenKey, pendingComments := false, []string{}
+	for _, line := range lines {
+		topLevel := line != "" && line[0] != ' ' && line[0] != '\n' && line[0] != '-'
+		switch {
+		case topLevel && line[0] == '#':
+			pendingComments = append(pendingComments, line)
+			continue
+		case topLevel:
+			if seenKey {
+				b.WriteString("\n")
+			}
+			seenKey = true
+		}
+		for _, c := range pendingComments {
+			b.WriteString(c)
+		}
+		pendingComments = pendingComments[:0]
+		b.WriteString(line)
+	}
+	for _, c := range pendingComments {
+		b.WriteString(c)
+	}
+	return []byte(b.String())
+}
+
+// formatMapping orders and filters a mapping node whose keys are described by t:
+// a struct type, the Extensions map type, or nil for free-form content
+func formatMapping(node *yaml.Node, t reflect.Type, prefix string, drop func(key, value string) bool) {
+	type pair struct {
+		key, value *yaml.Node
+		known      bool
+		order      int
+	}
+	var pairs []pair
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, val := node.Content[i], node.Content[i+1]
+		name := strings.ToLower(k.Value)
+		key := joinKey(prefix, name)
+		known, order := false, i // unknown keys keep their relative order last
+
+		childType := childType(t, name)
+		if t != nil && t.Kind() == reflect.Struct {
+			if sf, ok := fieldByKey(t, name); ok {
+				known, order = true, sf.Index[0]
+			}
+		}
+		switch {
+		case val.Kind == yaml.MappingNode && (childType != nil || t == nil || t.Kind() == reflect.Map):
+			formatMapping(val, childType, key, drop)
+			if len(val.Content) == 0 && drop != nil {
+				continue
+			}
+		case val.Kind == yaml.ScalarNode && childType != nil && drop != nil:
+			if drop(key, val.Value) {
+				continue
+			}
+		}
+		pairs = append(pairs, pair{k, val, known, order})
+	}
+
+	if t != nil && t.Kind() == reflect.Map {
+		sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].key.Value < pairs[j].key.Value })
+	} else {
+		sort.SliceStable(pairs, func(i, j int) bool {
+			if pairs[i].known != pairs[j].known {
+				return pairs[i].known
+			}
+			return pairs[i].order < pairs[j].order
+		})
+	}
+	node.Content = node.Content[:0]
+	for _, p := range pairs {
+		node.Content = append(node.Content, p.key, p.value)
+	}
+}
+
+// childType returns the type describing the value under name in a mapping of
+// type t: a struct field type, the schema of a registered extension, or nil
+func childType(t reflect.Type, name string) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Map {
+		extensionsMu.RLock()
+		defer extensionsMu.RUnlock()
+		return extensionSchemas[name]
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	sf, ok := fieldByKey(t, name)
+	if !ok {
+		return nil
+	}
+	return sf.Type
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const messyYAML = `# Header comment
+logging:
+    format: json
+    level: info # inline comment
+server:
+    # the port
+    port: 8080
+    custom: 1
+    host: localhost
+app:
+    name: demo
+extensions:
+    zeta: {b: 1}
+    alpha:
+        x: 1
+`
+
+const canonicalYAML = `# Header comment
+
+app:
+  name: demo
+
+server:
+  host: localhost
+  # the port
+  port: 8080
+  custom: 1
+
+logging:
+  level: info # inline comment
+  format: json
+
+extensions:
+  alpha:
+    x: 1
+  zeta: {b: 1}
+`
+
+func TestFormatYAML(t *testing.T) {
+	got, err := FormatYAML([]byte(messyYAML), nil)
+	if err != nil {
+		t.Fatalf("FormatYAML failed: %v", err)
+	}
+	if string(got) != canonicalYAML {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, canonicalYAML)
+	}
+
+	again, err := FormatYAML(got, nil)
+	if err != nil || string(again) != string(got) {
+		t.Errorf("Formatting is not idempotent:\n%s", again)
+	}
+}
+
+func TestFormatYAMLDrop(t *testing.T) {
+	var keys []string
+	got, err := FormatYAML([]byte(messyYAML), func(key, value string) bool {
+		keys = append(keys, key)
+		return key == "app.name" || key == "server.port"
+	})
+	if err != nil {
+		t.Fatalf("FormatYAML failed: %v", err)
+	}
+	out := string(got)
+	if strings.Contains(out, "app:") || strings.Contains(out, "port:") {
+		t.Errorf("Expected dropped keys and the emptied app section to be removed:\n%s", out)
+	}
+	for _, key := range keys {
+		if key == "server.custom" || strings.HasPrefix(key, "extensions.") {
+			t.Errorf("drop called for unknown or extension key %s", key)
+		}
+	}
+}
+
+func TestFormatYAMLRejectsNonMapping(t *testing.T) {
+	if _, err := FormatYAML([]byte("- a\n- b\n"), nil); err == nil {
+		t.Error("Expected error for a sequence document")
+	}
+}
+
+func TestFormatYAMLUnknownKeysLast(t *testing.T) {
+	got, err := FormatYAML([]byte("bogus: 1\nadmin:\n  enabled: true\napp:\n  name: demo\n"), nil)
+	if err != nil {
+		t.Fatalf("FormatYAML failed: %v", err)
+	}
+	want := "app:\n  name: demo\n\nadmin:\n  enabled: true\n\nbogus: 1\n"
+	if string(got) != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
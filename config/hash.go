@@ -0,0 +1,18 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Hash returns a stable SHA-256 fingerprint of the configuration values
+func (c *Config) Hash() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		// Config only holds JSON-encodable values; this cannot happen in practice
+		panic(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// sectionsKey is the context key carrying the sections ValidateSections covers
+type sectionsKey struct{}
+
+// volatileTags are validations that depend on state outside the configuration,
+// such as the file system, so their sections are revalidated on every reload
+var volatileTags = []string{"file", "dir", "filepath", "dirpath"}
+
+// RevalidationScope lists the top-level sections that must be revalidated when a
+// configuration that passed validation (old) is replaced by new: the sections
+// whose values changed, plus those with validations that depend on outside
+// state. full is set when only a full validation is safe: without old, or when
+// a change falls outside a section.
+func RevalidationScope(old, new *Config) (sections []string, full bool) {
+	if old == nil {
+		return nil, true
+	}
+	for _, change := range old.Diff(new) {
+		section, _, nested := strings.Cut(change.Key, ".")
+		if !nested {
+			return nil, true
+		}
+		if !slices.Contains(sections, section) {
+			sections = append(sections, section)
+		}
+	}
+	for _, section := range volatileSections() {
+		if !slices.Contains(sections, section) {
+			sections = append(sections, section)
+		}
+	}
+	return sections, false
+}
+
+// volatileSections lists the sections holding a field with a volatile validation
+func volatileSections() []string {
+	var sections []string
+	for _, field := range Fields() {
+		for _, tag := range strings.Split(field.Validate, ",") {
+			name, _, _ := strings.Cut(tag, "=")
+			section, _, _ := strings.Cut(field.Key, ".")
+			if slices.Contains(volatileTags, name) && !slices.Contains(sections, section) {
+				sections = append(sections, section)
+			}
+		}
+	}
+	return sections
+}
+
+// ValidateSections validates the given top-level sections of c and the
+// cross-section rules that reference them, skipping everything else. It is only
+// sound when c differs from a configuration that passed validation in those
+// sections alone; use RevalidationScope to find them.
+func ValidateSections(validate *validator.Validate, c *Config, sections []string) error {
+	names := map[string]bool{}
+	t := reflect.TypeOf(Config{})
+	for _, section := range sections {
+		if sf, ok := fieldByKey(t, section); ok {
+			names[t.Name()+"."+sf.Name] = true
+		}
+	}
+	ctx := context.WithValue(context.Background(), sectionsKey{}, sections)
+	return validate.StructFilteredCtx(ctx, c, func(ns []byte) bool {
+		// ns is "Config.<Section>" for top-level fields and longer below them
+		parts := strings.SplitN(string(ns), ".", 3)
+		return len(parts) < 2 || !names[parts[0]+"."+parts[1]]
+	})
+}
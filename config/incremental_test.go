@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestRevalidationScope(t *testing.T) {
+	if _, full := RevalidationScope(nil, sampleConfig()); !full {
+		t.Error("Expected full validation without a previous configuration")
+	}
+
+	old := sampleConfig()
+	updated := sampleConfig()
+	updated.Database.Port = 5433
+	updated.Extensions["tags"] = []string{"z"}
+	sections, full := RevalidationScope(old, updated)
+	if full {
+		t.Fatal("Expected incremental validation")
+	}
+	// server holds the TLS files, which are checked on the file system every time
+	if want := []string{"database", "extensions", "server"}; !reflect.DeepEqual(sections, want) {
+		t.Errorf("RevalidationScope = %v, want %v", sections, want)
+	}
+}
+
+func TestValidateSections(t *testing.T) {
+	cfg := sampleConfig()
+	cfg.Server.Port = 99999         // out of range, in a section that is not validated
+	cfg.Database.Host = "localhost" // same host as the server in production
+	validate := NewValidator()
+
+	if err := ValidateSections(validate, cfg, []string{"metrics"}); err != nil {
+		t.Errorf("Expected unvalidated sections to be skipped, got %v", err)
+	}
+
+	var validationErrors validator.ValidationErrors
+	if err := ValidateSections(validate, cfg, []string{"database"}); !errors.As(err, &validationErrors) {
+		t.Fatalf("Expected validation errors, got %v", err)
+	}
+	if len(validationErrors) != 1 || validationErrors[0].Tag() != "rule" {
+		t.Errorf("Expected the production_database_host rule to fail, got %v", validationErrors)
+	}
+
+	// The rule also reads app.environment, so a change there reruns it
+	if err := ValidateSections(validate, cfg, []string{"app"}); !errors.As(err, &validationErrors) {
+		t.Errorf("Expected the rule referencing app to run, got %v", err)
+	}
+
+	if err := ValidateSections(validate, cfg, []string{"server"}); !errors.As(err, &validationErrors) || validationErrors[0].Tag() != "lte" {
+		t.Errorf("Expected server.port to fail, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Lookup returns the value at a dotted key such as "server.port": a field, a
+// whole section such as "server", or an entry of the extensions. ok is false
+// when no such key exists.
+func (c *Config) Lookup(key string) (value any, ok bool) {
+	v := reflect.ValueOf(*c)
+	for _, part := range strings.Split(key, ".") {
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			sf, found := fieldByKey(v.Type(), part)
+			if !found {
+				return nil, false
+			}
+			v = v.FieldByIndex(sf.Index)
+		case reflect.Map:
+			v = mapValue(v, reflect.ValueOf(part))
+			if !v.IsValid() {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+	return v.Interface(), true
+}
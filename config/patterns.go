@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Pattern is a named regular expression that string fields reference with
+// validate:"pattern=<name>". The same expression is emitted in the JSON Schema,
+// so validation and documentation cannot drift apart.
+type Pattern struct {
+	Name        string
+	Expr        string
+	Description string
+	re          *regexp.Regexp
+}
+
+var (
+	patternsMu sync.RWMutex
+	patterns   = map[string]Pattern{}
+)
+
+func init() {
+	mustRegisterPattern("dns_label", `^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`,
+		"RFC 1123 DNS label: lowercase alphanumerics and '-', at most 63 characters")
+	mustRegisterPattern("k8s_name", `^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`,
+		"Kubernetes resource name: DNS labels separated by '.'")
+	mustRegisterPattern("identifier", `^[A-Za-z_][A-Za-z0-9_]*$`,
+		"identifier: letters, digits and '_', not starting with a digit")
+}
+
+// RegisterPattern adds a named pattern. The expression must compile and should
+// be anchored; it should also stay within the syntax shared by Go and JSON
+// Schema (ECMA-262) regular expressions.
+func RegisterPattern(name, expr, description string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("registering pattern %q: %w", name, err)
+	}
+
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+	if _, ok := patterns[name]; ok {
+		return fmt.Errorf("pattern %q is already registered", name)
+	}
+	patterns[name] = Pattern{Name: name, Expr: expr, Description: description, re: re}
+	return nil
+}
+
+func mustRegisterPattern(name, expr, description string) {
+	if err := RegisterPattern(name, expr, description); err != nil {
+		panic(err)
+	}
+}
+
+// LookupPattern returns the pattern registered under name
+func LookupPattern(name string) (Pattern, bool) {
+	patternsMu.RLock()
+	defer patternsMu.RUnlock()
+	p, ok := patterns[name]
+	return p, ok
+}
+
+// Patterns returns every registered pattern, sorted by name
+func Patterns() []Pattern {
+	patternsMu.RLock()
+	defer patternsMu.RUnlock()
+	list := make([]Pattern, 0, len(patterns))
+	for _, p := range patterns {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Match reports whether s matches the pattern
+func (p Pattern) Match(s string) bool {
+	return p.re.MatchString(s)
+}
+
+// NewValidator returns a validator with the custom validations used by the
+// configuration tags and the cross-section rules registered
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	if err := validate.RegisterValidation("pattern", validatePattern); err != nil {
+		panic(err)
+	}
+	validate.RegisterStructValidationCtx(validateRules, Config{})
+	return validate
+}
+
+// validatePattern implements validate:"pattern=<name>". Unknown pattern names
+// fail validation; TestPatternTagsAreRegistered catches them for Config.
+func validatePattern(fl validator.FieldLevel) bool {
+	p, ok := LookupPattern(fl.Param())
+	if !ok || fl.Field().Kind() != reflect.String {
+		return false
+	}
+	return p.Match(fl.Field().String())
+}
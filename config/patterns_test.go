@@ -0,0 +1,81 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"dns_label", "my-service", true},
+		{"dns_label", "My-Service", false},
+		{"dns_label", "-leading", false},
+		{"dns_label", strings.Repeat("a", 64), false},
+		{"k8s_name", "app.example-1", true},
+		{"k8s_name", "app..example", false},
+		{"identifier", "my_db2", true},
+		{"identifier", "2db", false},
+		{"identifier", "my-db", false},
+	}
+	for _, tt := range tests {
+		p, ok := LookupPattern(tt.pattern)
+		if !ok {
+			t.Fatalf("Pattern %s is not registered", tt.pattern)
+		}
+		if got := p.Match(tt.value); got != tt.want {
+			t.Errorf("%s.Match(%q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPatternTagsAreRegistered(t *testing.T) {
+	for _, field := range Fields() {
+		for _, rule := range strings.Split(field.Validate, ",") {
+			if name, ok := strings.CutPrefix(rule, "pattern="); ok {
+				if _, found := LookupPattern(name); !found {
+					t.Errorf("%s references unknown pattern %q", field.Key, name)
+				}
+			}
+		}
+	}
+}
+
+func TestRegisterPatternErrors(t *testing.T) {
+	if err := RegisterPattern("identifier", `^x$`, ""); err == nil {
+		t.Error("Expected duplicate registration to fail")
+	}
+	if err := RegisterPattern("broken", `^(x$`, ""); err == nil {
+		t.Error("Expected invalid expression to fail")
+	}
+}
+
+func TestPatternValidation(t *testing.T) {
+	validate := NewValidator()
+	type sample struct {
+		Name string `validate:"omitempty,pattern=identifier"`
+		Host string `validate:"pattern=unknown"`
+	}
+	err := validate.Struct(sample{Name: "ok_name", Host: "x"})
+	if err == nil || !strings.Contains(err.Error(), "Host") || strings.Contains(err.Error(), "'Name'") {
+		t.Errorf("Expected only the unknown pattern to fail, got %v", err)
+	}
+	if err := validate.Struct(sample{Name: "bad-name"}); err == nil || !strings.Contains(err.Error(), "'Name'") {
+		t.Errorf("Expected bad-name to fail, got %v", err)
+	}
+}
+
+func TestSchemaIncludesPatterns(t *testing.T) {
+	type sample struct {
+		Name string `mapstructure:"name" validate:"omitempty,pattern=identifier"`
+	}
+	name := structSchema(reflect.TypeOf(sample{}))["properties"].(map[string]any)["name"].(map[string]any)
+	identifier, _ := LookupPattern("identifier")
+	if name["pattern"] != identifier.Expr || name["description"] != identifier.Description {
+		t.Errorf("Expected the schema to carry the identifier pattern, got %v", name)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import "reflect"
+
+// RewriteStrings replaces every string setting of c, including strings nested in
+// Extensions, with the result of fn. fn receives the dotted key of the setting;
+// the first error aborts the rewrite.
+func (c *Config) RewriteStrings(fn func(key, value string) (string, error)) error {
+	if err := rewriteStruct("", reflect.ValueOf(c).Elem(), fn); err != nil {
+		return err
+	}
+	for name, ext := range c.Extensions {
+		rewritten, err := rewriteAny(joinKey("extensions", name), ext, fn)
+		if err != nil {
+			return err
+		}
+		c.Extensions[name] = rewritten
+	}
+	return nil
+}
+
+func rewriteStruct(prefix string, v reflect.Value, fn func(key, value string) (string, error)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := joinKey(prefix, fieldKey(sf))
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			if err := rewriteStruct(key, field, fn); err != nil {
+				return err
+			}
+		case reflect.String:
+			s, err := fn(key, field.String())
+			if err != nil {
+				return err
+			}
+			field.SetString(s)
+		}
+	}
+	return nil
+}
+
+// rewriteAny rewrites strings inside the generic maps and slices produced by
+// the config decoders
+func rewriteAny(key string, value any, fn func(key, value string) (string, error)) (any, error) {
+	switch val := value.(type) {
+	case string:
+		return fn(key, val)
+	case map[string]any:
+		for k, item := range val {
+			rewritten, err := rewriteAny(joinKey(key, k), item, fn)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = rewritten
+		}
+	case []any:
+		for i, item := range val {
+			rewritten, err := rewriteAny(key, item, fn)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = rewritten
+		}
+	}
+	return value, nil
+}
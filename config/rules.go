@@ -0,0 +1,253 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// crossRules lists the constraints spanning several sections. They run as a
+// struct-level validation of Config, after the field tags.
+var crossRules = []Rule{
+	Distinct("distinct_ports",
+		PortOf("server.port"),
+		PortOf("metrics.listen").If(Equals("metrics.enabled", true)),
+		PortOf("admin.listen").If(Equals("admin.enabled", true)),
+	),
+	NotEqual("production_database_host", Key("database.host"), Key("server.host")).
+		When(Equals("app.environment", "production")),
+}
+
+// Rule is a declarative constraint over several configuration keys, built with
+// Distinct or NotEqual and optionally restricted with When
+type Rule struct {
+	Name  string
+	when  Condition
+	check func(settings map[string]any) []Violation
+	// keys lists every key the rule reads, including those of its conditions
+	keys []string
+}
+
+// Violation is a single failure of a rule, attributed to one key. Severity is
+// only set for rules-file constraints; cross-section rules are always errors.
+type Violation struct {
+	Rule     string
+	Key      string
+	Value    any
+	Message  string
+	Severity string
+}
+
+// Condition reports whether a rule or operand applies to the settings at Key
+type Condition struct {
+	Key   string
+	holds func(settings map[string]any) bool
+}
+
+// Equals holds when the setting at key equals value
+func Equals(key string, value any) Condition {
+	return Condition{Key: key, holds: func(settings map[string]any) bool {
+		return settings[key] == value
+	}}
+}
+
+// Operand selects the value a rule compares from the settings
+type Operand struct {
+	Key   string
+	value func(v any) (any, bool)
+	cond  Condition
+}
+
+// Key selects the setting at key; empty values are ignored
+func Key(key string) Operand {
+	return Operand{Key: key, value: func(v any) (any, bool) {
+		return v, v != nil && !reflect.ValueOf(v).IsZero()
+	}}
+}
+
+// PortOf selects the port of a setting holding either a port number or a
+// host:port address
+func PortOf(key string) Operand {
+	return Operand{Key: key, value: func(v any) (any, bool) {
+		switch val := v.(type) {
+		case int:
+			return val, val != 0
+		case string:
+			_, port, err := net.SplitHostPort(val)
+			if err != nil {
+				return nil, false
+			}
+			n, err := strconv.Atoi(port)
+			return n, err == nil && n != 0
+		}
+		return nil, false
+	}}
+}
+
+// If restricts the operand to settings where cond holds
+func (o Operand) If(cond Condition) Operand {
+	o.cond = cond
+	return o
+}
+
+func (o Operand) resolve(settings map[string]any) (any, bool) {
+	if o.cond.holds != nil && !o.cond.holds(settings) {
+		return nil, false
+	}
+	return o.value(settings[o.Key])
+}
+
+// keys lists the keys the operand reads
+func (o Operand) keys() []string {
+	if o.cond.Key != "" {
+		return []string{o.Key, o.cond.Key}
+	}
+	return []string{o.Key}
+}
+
+// Distinct requires every applicable operand to have a different value
+func Distinct(name string, operands ...Operand) Rule {
+	var keys []string
+	for _, op := range operands {
+		keys = append(keys, op.keys()...)
+	}
+	return Rule{Name: name, keys: keys, check: func(settings map[string]any) []Violation {
+		var violations []Violation
+		seen := map[any]string{}
+		for _, op := range operands {
+			value, ok := op.resolve(settings)
+			if !ok {
+				continue
+			}
+			if first, dup := seen[value]; dup {
+				violations = append(violations, Violation{
+					Key:     op.Key,
+					Value:   settings[op.Key],
+					Message: fmt.Sprintf("a value different from %s (both are %v)", first, value),
+				})
+				continue
+			}
+			seen[value] = op.Key
+		}
+		return violations
+	}}
+}
+
+// NotEqual requires the operands to differ when both apply
+func NotEqual(name string, a, b Operand) Rule {
+	return Rule{Name: name, keys: append(a.keys(), b.keys()...), check: func(settings map[string]any) []Violation {
+		va, okA := a.resolve(settings)
+		vb, okB := b.resolve(settings)
+		if !okA || !okB || va != vb {
+			return nil
+		}
+		return []Violation{{Key: a.Key, Value: settings[a.Key], Message: "a value different from " + b.Key}}
+	}}
+}
+
+// When restricts the rule to settings where cond holds
+func (r Rule) When(cond Condition) Rule {
+	r.when = cond
+	r.keys = append(slices.Clip(r.keys), cond.Key)
+	return r
+}
+
+// references reports whether the rule reads a key of one of the sections
+func (r Rule) references(sections []string) bool {
+	for _, key := range r.keys {
+		section, _, _ := strings.Cut(key, ".")
+		if slices.Contains(sections, section) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate applies the rule to the flattened settings
+func (r Rule) evaluate(settings map[string]any) []Violation {
+	if r.when.holds != nil && !r.when.holds(settings) {
+		return nil
+	}
+	violations := r.check(settings)
+	for i := range violations {
+		violations[i].Rule = r.Name
+	}
+	return violations
+}
+
+// CheckRules evaluates every cross-section rule against c
+func CheckRules(c *Config) []Violation {
+	return checkRules(c, crossRules)
+}
+
+func checkRules(c *Config, rules []Rule) []Violation {
+	settings := settingsMap(c)
+	var violations []Violation
+	for _, r := range rules {
+		violations = append(violations, r.evaluate(settings)...)
+	}
+	return violations
+}
+
+func settingsMap(c *Config) map[string]any {
+	settings := map[string]any{}
+	for _, s := range c.Settings() {
+		settings[s.Key] = s.Value
+	}
+	return settings
+}
+
+// validateRules is the struct-level validation of Config reporting rule
+// violations as "rule" field errors, so they are reported like tag failures.
+// The error parameter carries the violation message. Under ValidateSections only
+// the rules referencing the validated sections run.
+func validateRules(ctx context.Context, sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(Config)
+	rules := crossRules
+	if sections, ok := ctx.Value(sectionsKey{}).([]string); ok {
+		rules = nil
+		for _, r := range crossRules {
+			if r.references(sections) {
+				rules = append(rules, r)
+			}
+		}
+	}
+	for _, violation := range checkRules(&cfg, rules) {
+		field := namespaceForKey(violation.Key)
+		sl.ReportError(violation.Value, field, field, "rule", violation.Message)
+	}
+}
+
+// namespaceForKey converts a dotted key such as "server.port" into the struct
+// field path "Server.Port", the inverse of KeyForNamespace
+func namespaceForKey(key string) string {
+	t := reflect.TypeOf(Config{})
+	var names []string
+	for _, part := range strings.Split(key, ".") {
+		if t.Kind() != reflect.Struct {
+			return key
+		}
+		sf, ok := fieldByKey(t, part)
+		if !ok {
+			return key
+		}
+		names = append(names, sf.Name)
+		t = sf.Type
+	}
+	return strings.Join(names, ".")
+}
+
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); fieldKey(sf) == key {
+			return sf, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestCheckRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string // keys of the expected violations
+	}{
+		{name: "Sample Is Valid", modify: func(c *Config) {}},
+		{
+			name: "Metrics Port Clash",
+			modify: func(c *Config) {
+				c.Metrics = MetricsConfig{Enabled: true, Listen: ":8080"}
+			},
+			want: []string{"metrics.listen"},
+		},
+		{
+			name: "Disabled Listener Ignored",
+			modify: func(c *Config) {
+				c.Metrics = MetricsConfig{Enabled: false, Listen: ":8080"}
+			},
+		},
+		{
+			name: "Admin Clashes With Metrics",
+			modify: func(c *Config) {
+				c.Metrics = MetricsConfig{Enabled: true, Listen: "127.0.0.1:9090"}
+				c.Admin = AdminConfig{Enabled: true, Listen: ":9090"}
+			},
+			want: []string{"admin.listen"},
+		},
+		{
+			name: "Production Database On Server Host",
+			modify: func(c *Config) {
+				c.Database.Host = c.Server.Host
+			},
+			want: []string{"database.host"},
+		},
+		{
+			name: "Shared Host Outside Production",
+			modify: func(c *Config) {
+				c.App.Environment = "development"
+				c.Database.Host = c.Server.Host
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := sampleConfig()
+			tt.modify(cfg)
+			violations := CheckRules(cfg)
+			if len(violations) != len(tt.want) {
+				t.Fatalf("Expected %d violation(s), got %+v", len(tt.want), violations)
+			}
+			for i, key := range tt.want {
+				if violations[i].Key != key {
+					t.Errorf("Expected violation on %s, got %+v", key, violations[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRulesReportedAsFieldErrors(t *testing.T) {
+	cfg := sampleConfig()
+	cfg.Metrics = MetricsConfig{Enabled: true, Listen: ":8080"}
+
+	var validationErrors validator.ValidationErrors
+	if err := NewValidator().Struct(cfg); !errors.As(err, &validationErrors) {
+		t.Fatalf("Expected validation errors, got %v", err)
+	}
+	if len(validationErrors) != 1 {
+		t.Fatalf("Expected 1 error, got %v", validationErrors)
+	}
+	fieldErr := validationErrors[0]
+	if fieldErr.Tag() != "rule" || KeyForNamespace(fieldErr.Namespace()) != "metrics.listen" {
+		t.Errorf("Expected rule error on metrics.listen, got %s on %s", fieldErr.Tag(), fieldErr.Namespace())
+	}
+}
+
+func TestNamespaceForKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"server.port":                  "Server.Port",
+		"server.shutdown.grace_period": "Server.Shutdown.GracePeriod",
+		"server.port.extra":            "server.port.extra",
+		"unknown.key":                  "unknown.key",
+	} {
+		if got := namespaceForKey(key); got != want {
+			t.Errorf("namespaceForKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Severities of rules-file constraints: errors fail the load, warnings are reported
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// FileRule is a site-specific constraint declared in a rules file and evaluated
+// after struct validation, e.g.
+//
+//	rules:
+//	  - key: server.port
+//	    operator: gte
+//	    value: 8000
+//	    severity: warning
+//	    message: ports below 8000 are reserved for system services
+type FileRule struct {
+	Key      string `yaml:"key"`
+	Operator string `yaml:"operator"`
+	Value    any    `yaml:"value"`
+	Severity string `yaml:"severity"`
+	Message  string `yaml:"message"`
+
+	field   Field
+	operand any   // Value converted to the key's type
+	list    []any // converted operands of in and not_in
+	re      *regexp.Regexp
+}
+
+// ruleOperators lists the supported operators and whether they take a value
+var ruleOperators = map[string]bool{
+	"eq": true, "ne": true,
+	"gt": true, "gte": true, "lt": true, "lte": true,
+	"in": true, "not_in": true,
+	"matches":  true,
+	"required": false,
+}
+
+// RulesError reports the error-severity violations of a rules file
+type RulesError struct {
+	Path       string
+	Violations []Violation
+}
+
+func (e *RulesError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = fmt.Sprintf("%s: %s (current value: %v)", v.Key, v.Message, v.Value)
+	}
+	return fmt.Sprintf("rules file %s: %s", e.Path, strings.Join(msgs, "; "))
+}
+
+// LoadRulesFile reads and checks the rules file at path
+func LoadRulesFile(path string) ([]FileRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseRules decodes a rules document. Unknown keys, operators, severities and
+// operands that do not fit the key's type are rejected up front, so a typo
+// cannot silently disable a policy.
+func ParseRules(data []byte) ([]FileRule, error) {
+	var doc struct {
+		Rules []FileRule `yaml:"rules"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	fields := map[string]Field{}
+	for _, f := range Fields() {
+		fields[f.Key] = f
+	}
+	var errs []error
+	for i := range doc.Rules {
+		if err := doc.Rules[i].compile(fields); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d (%s): %w", i+1, doc.Rules[i].Key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return doc.Rules, nil
+}
+
+func (r *FileRule) compile(fields map[string]Field) error {
+	field, ok := fields[r.Key]
+	if !ok {
+		return fmt.Errorf("unknown key %q", r.Key)
+	}
+	r.field = field
+
+	takesValue, ok := ruleOperators[r.Operator]
+	if !ok {
+		return fmt.Errorf("unknown operator %q", r.Operator)
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityError
+	case SeverityError, SeverityWarning:
+	default:
+		return fmt.Errorf("unknown severity %q (expected error or warning)", r.Severity)
+	}
+	if takesValue && r.Value == nil {
+		return fmt.Errorf("operator %s requires a value", r.Operator)
+	}
+
+	var err error
+	switch r.Operator {
+	case "gt", "gte", "lt", "lte":
+		if !isOrdered(field.Type) {
+			return fmt.Errorf("operator %s needs a numeric or duration key, %s is %s", r.Operator, r.Key, field.TypeName())
+		}
+		r.operand, err = coerce(field.Type, r.Value)
+	case "eq", "ne":
+		r.operand, err = coerce(field.Type, r.Value)
+	case "in", "not_in":
+		items, ok := r.Value.([]any)
+		if !ok {
+			return fmt.Errorf("operator %s requires a list value", r.Operator)
+		}
+		for _, item := range items {
+			operand, err := coerce(field.Type, item)
+			if err != nil {
+				return err
+			}
+			r.list = append(r.list, operand)
+		}
+	case "matches":
+		expr, ok := r.Value.(string)
+		if !ok || field.Type.Kind() != reflect.String {
+			return fmt.Errorf("operator matches requires a string key and a regular expression")
+		}
+		r.re, err = regexp.Compile(expr)
+	}
+	if err != nil {
+		return err
+	}
+	if r.Message == "" {
+		r.Message = r.defaultMessage()
+	}
+	return nil
+}
+
+func (r *FileRule) defaultMessage() string {
+	switch r.Operator {
+	case "required":
+		return "must be set"
+	case "matches":
+		return fmt.Sprintf("must match %v", r.Value)
+	default:
+		return fmt.Sprintf("must be %s %v", strings.ReplaceAll(r.Operator, "_", " "), r.Value)
+	}
+}
+
+// holds reports whether the constraint is satisfied by a setting value
+func (r *FileRule) holds(value any) bool {
+	v := normalize(value)
+	switch r.Operator {
+	case "eq":
+		return v == r.operand
+	case "ne":
+		return v != r.operand
+	case "gt":
+		return v.(int64) > r.operand.(int64)
+	case "gte":
+		return v.(int64) >= r.operand.(int64)
+	case "lt":
+		return v.(int64) < r.operand.(int64)
+	case "lte":
+		return v.(int64) <= r.operand.(int64)
+	case "in", "not_in":
+		found := false
+		for _, item := range r.list {
+			if v == item {
+				found = true
+				break
+			}
+		}
+		return found == (r.Operator == "in")
+	case "matches":
+		return r.re.MatchString(v.(string))
+	case "required":
+		return !reflect.ValueOf(value).IsZero()
+	}
+	return false
+}
+
+// CheckFileRules evaluates rules against c and returns every violation, each
+// carrying the severity of its rule
+func CheckFileRules(c *Config, rules []FileRule) []Violation {
+	settings := settingsMap(c)
+	var violations []Violation
+	for i := range rules {
+		r := &rules[i]
+		value := settings[r.Key]
+		if r.holds(value) {
+			continue
+		}
+		if r.field.Secret && fmt.Sprint(value) != "" {
+			value = Redacted
+		}
+		violations = append(violations, Violation{
+			Rule:     r.Operator,
+			Key:      r.Key,
+			Value:    value,
+			Message:  r.Message,
+			Severity: r.Severity,
+		})
+	}
+	return violations
+}
+
+func isOrdered(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// normalize maps setting values onto comparable representations: signed
+// integers and durations to int64, strings and booleans as-is
+func normalize(value any) any {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	}
+	return value
+}
+
+// coerce converts a rules-file operand to the normalized form of type t
+func coerce(t reflect.Type, value any) (any, error) {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		if s, ok := value.(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, err
+			}
+			return int64(d), nil
+		}
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := value.(int); ok {
+			return int64(n), nil
+		}
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("value %v does not fit a %s key", value, Field{Type: t}.TypeName())
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleRules = `
+rules:
+  - key: server.port
+    operator: gte
+    value: 9000
+    severity: warning
+  - key: logging.level
+    operator: in
+    value: [info, warn]
+  - key: server.shutdown.grace_period
+    operator: lte
+    value: 1m
+  - key: database.password
+    operator: ne
+    value: secret
+    message: change the example password
+  - key: database.name
+    operator: matches
+    value: ^db
+  - key: database.username
+    operator: required
+`
+
+func TestCheckFileRules(t *testing.T) {
+	rules, err := ParseRules([]byte(sampleRules))
+	if err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+
+	cfg := sampleConfig()
+	cfg.Server.Shutdown.GracePeriod = 2 * time.Minute
+	violations := CheckFileRules(cfg, rules)
+
+	want := map[string]string{
+		"server.port":                  SeverityWarning,
+		"server.shutdown.grace_period": SeverityError,
+		"database.password":            SeverityError,
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("Expected %d violations, got %+v", len(want), violations)
+	}
+	for _, v := range violations {
+		if severity, ok := want[v.Key]; !ok || v.Severity != severity {
+			t.Errorf("Unexpected violation %+v", v)
+		}
+		if v.Key == "database.password" {
+			if v.Value != Redacted || v.Message != "change the example password" {
+				t.Errorf("Expected redacted value and custom message, got %+v", v)
+			}
+		}
+	}
+}
+
+func TestParseRulesRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{name: "Unknown Key", doc: "rules: [{key: server.prt, operator: eq, value: 1}]", want: `unknown key "server.prt"`},
+		{name: "Unknown Operator", doc: "rules: [{key: server.port, operator: between, value: 1}]", want: `unknown operator "between"`},
+		{name: "Unknown Severity", doc: "rules: [{key: server.port, operator: eq, value: 1, severity: fatal}]", want: `unknown severity "fatal"`},
+		{name: "Missing Value", doc: "rules: [{key: server.port, operator: eq}]", want: "requires a value"},
+		{name: "Wrong Type", doc: "rules: [{key: server.port, operator: eq, value: high}]", want: "does not fit a int key"},
+		{name: "Ordered String", doc: "rules: [{key: app.name, operator: gt, value: a}]", want: "needs a numeric or duration key"},
+		{name: "Bad Regex", doc: "rules: [{key: app.name, operator: matches, value: '('}]", want: "missing closing )"},
+		{name: "Unknown Field", doc: "rules: [{key: app.name, operatr: eq}]", want: "field operatr not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if rules, err := ParseRules(nil); err != nil || len(rules) != 0 {
+		t.Errorf("Expected empty document to yield no rules, got %v, %v", rules, err)
+	}
+}
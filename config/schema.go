@@ -0,0 +1,138 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaURI identifies the JSON Schema dialect produced by Schema
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns a JSON Schema for the configuration file, derived from the same
+// struct tags used for validation. "required" is not emitted, since required
+// values may also come from flags or the environment.
+func Schema() map[string]any {
+	schema := structSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = SchemaURI
+	schema["title"] = "Configuration"
+	return schema
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		switch sf.Type.Kind() {
+		case reflect.Struct:
+			props[fieldKey(sf)] = structSchema(sf.Type)
+		case reflect.Map:
+			props[fieldKey(sf)] = extensionsSchema()
+		default:
+			props[fieldKey(sf)] = fieldSchema(sf)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+// extensionsSchema describes registered extensions; others are free-form
+func extensionsSchema() map[string]any {
+	extensionsMu.RLock()
+	defer extensionsMu.RUnlock()
+	props := map[string]any{}
+	for name, t := range extensionSchemas {
+		props[name] = structSchema(t)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": true,
+	}
+}
+
+// fieldSchema maps a leaf field and its validate tag onto JSON Schema keywords
+func fieldSchema(sf reflect.StructField) map[string]any {
+	schema := map[string]any{}
+	numeric := false
+	switch kind := sf.Type.Kind(); {
+	case sf.Type == reflect.TypeOf(time.Duration(0)):
+		schema["type"] = "string"
+		schema["description"] = "duration, e.g. 30s or 1m30s"
+	case kind == reflect.String:
+		schema["type"] = "string"
+	case kind == reflect.Bool:
+		schema["type"] = "boolean"
+	case kind >= reflect.Int && kind <= reflect.Uint64:
+		schema["type"] = "integer"
+		numeric = true
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		schema["type"] = "number"
+		numeric = true
+	case kind == reflect.Slice:
+		schema["type"] = "array"
+	}
+	if isSecret(sf) {
+		schema["writeOnly"] = true
+	}
+	if msg := sf.Tag.Get("deprecated"); msg != "" {
+		schema["deprecated"] = true
+		schema["description"] = "Deprecated: " + msg
+	}
+
+	isString := schema["type"] == "string" && sf.Type.Kind() == reflect.String
+	for _, rule := range strings.Split(sf.Tag.Get("validate"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "oneof":
+			var enum []any
+			for _, value := range strings.Fields(param) {
+				if n, err := strconv.ParseFloat(value, 64); numeric && err == nil {
+					enum = append(enum, n)
+				} else {
+					enum = append(enum, value)
+				}
+			}
+			schema["enum"] = enum
+		case "gte", "min", "lte", "max", "gt", "lt", "len":
+			setBound(schema, name, param, numeric, isString)
+		case "pattern":
+			if p, ok := LookupPattern(param); ok {
+				schema["pattern"] = p.Expr
+				schema["description"] = p.Description
+			}
+		}
+	}
+	return schema
+}
+
+// setBound translates a numeric or length constraint
+func setBound(schema map[string]any, rule, param string, numeric, isString bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch {
+	case numeric:
+		keyword := map[string]string{"gte": "minimum", "min": "minimum", "lte": "maximum", "max": "maximum", "gt": "exclusiveMinimum", "lt": "exclusiveMaximum"}[rule]
+		if keyword != "" {
+			schema[keyword] = n
+		}
+	case isString:
+		switch rule {
+		case "gte", "min":
+			schema["minLength"] = n
+		case "lte", "max":
+			schema["maxLength"] = n
+		case "len":
+			schema["minLength"], schema["maxLength"] = n, n
+		}
+	}
+}
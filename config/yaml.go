@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// YAML renders the configuration as a YAML document using the same keys viper
+// reads, with sections and keys in struct declaration order
+func (c *Config) YAML() ([]byte, error) {
+	return MarshalYAMLValue(*c)
+}
+
+// MarshalYAMLValue renders part of a configuration, such as a section returned
+// by Lookup, the way YAML renders the whole of it
+func MarshalYAMLValue(value any) ([]byte, error) {
+	node, err := encodeNode(reflect.ValueOf(value))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeNode(v reflect.Value) (*yaml.Node, error) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return scalarNode(nil)
+		}
+		v = v.Elem()
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return scalarNode(d.String())
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Map && fv.Len() == 0 {
+				continue
+			}
+			child, err := encodeNode(fv)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, keyNode(fieldKey(sf)), child)
+		}
+		return node, nil
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			child, err := encodeNode(v.MapIndex(k))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, keyNode(fmt.Sprint(k.Interface())), child)
+		}
+		return node, nil
+	case reflect.Slice, reflect.Array:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := 0; i < v.Len(); i++ {
+			child, err := encodeNode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	default:
+		return scalarNode(v.Interface())
+	}
+}
+
+func keyNode(key string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+}
+
+func scalarNode(value any) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
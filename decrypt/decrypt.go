@@ -0,0 +1,195 @@
+// Package decrypt detects and decrypts encrypted configuration documents:
+// whole files encrypted with age, and SOPS-encrypted YAML/JSON files.
+package decrypt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"go.yaml.in/yaml/v3"
+)
+
+// Encryption schemes recognized by Detect
+const (
+	None = ""
+	Age  = "age"
+	SOPS = "sops"
+)
+
+// Environment variables providing age identities, in addition to the standard
+// SOPS locations
+const (
+	KeyEnv     = "MYAPP_AGE_KEY"
+	KeyFileEnv = "MYAPP_AGE_KEY_FILE"
+)
+
+var (
+	ageHeader      = []byte("age-encryption.org/v1\n")
+	ageArmorHeader = []byte(armor.Header)
+)
+
+// Detect reports how a configuration document in the given format is encrypted
+func Detect(data []byte, format string) string {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if bytes.HasPrefix(trimmed, ageHeader) || bytes.HasPrefix(trimmed, ageArmorHeader) {
+		return Age
+	}
+	if sopsFormat(format) != "" && isSOPS(data) {
+		return SOPS
+	}
+	return None
+}
+
+// Decrypt returns the plaintext of an encrypted document, or data unchanged
+// when it is not encrypted
+func Decrypt(data []byte, format string) ([]byte, error) {
+	switch Detect(data, format) {
+	case Age:
+		return decryptAge(data)
+	case SOPS:
+		return decryptSOPS(data, format)
+	default:
+		return data, nil
+	}
+}
+
+func decryptAge(data []byte) ([]byte, error) {
+	identities, err := Identities()
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("config is age-encrypted but no age identity was found (set %s or %s)", KeyEnv, KeyFileEnv)
+	}
+
+	var in io.Reader = bytes.NewReader(bytes.TrimLeft(data, " \t\r\n"))
+	if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), ageArmorHeader) {
+		in = armor.NewReader(in)
+	}
+	r, err := age.Decrypt(in, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting age-encrypted config: %w", err)
+	}
+	return io.ReadAll(r)
+}
+
+// decryptSOPS shells out to the sops binary, which handles every key service
+// SOPS supports. Identities from MYAPP_AGE_KEY/MYAPP_AGE_KEY_FILE are passed on.
+func decryptSOPS(data []byte, format string) ([]byte, error) {
+	bin, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, errors.New("config is SOPS-encrypted but the sops binary was not found in PATH")
+	}
+	// sops reads a file argument, and /dev/stdin does not exist on Windows.
+	// CreateTemp creates the file readable only by the owner.
+	tmp, err := os.CreateTemp("", "myapp-sops-*")
+	if err != nil {
+		return nil, fmt.Errorf("decrypting SOPS-encrypted config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decrypting SOPS-encrypted config: %w", err)
+	}
+
+	f := sopsFormat(format)
+	cmd := exec.Command(bin, "--decrypt", "--input-type", f, "--output-type", f, tmp.Name())
+	cmd.Env = os.Environ()
+	if key := os.Getenv(KeyEnv); key != "" {
+		cmd.Env = append(cmd.Env, "SOPS_AGE_KEY="+key)
+	}
+	if keyFile := os.Getenv(KeyFileEnv); keyFile != "" {
+		cmd.Env = append(cmd.Env, "SOPS_AGE_KEY_FILE="+keyFile)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("decrypting SOPS-encrypted config: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// Identities collects age identities from MYAPP_AGE_KEY, MYAPP_AGE_KEY_FILE,
+// SOPS_AGE_KEY, SOPS_AGE_KEY_FILE, and the default SOPS key file
+// (<user config dir>/sops/age/keys.txt), in that order
+func Identities() ([]age.Identity, error) {
+	var identities []age.Identity
+	add := func(source string, r io.Reader) error {
+		ids, err := age.ParseIdentities(r)
+		if err != nil {
+			return fmt.Errorf("parsing age identities from %s: %w", source, err)
+		}
+		identities = append(identities, ids...)
+		return nil
+	}
+
+	for _, env := range []string{KeyEnv, "SOPS_AGE_KEY"} {
+		if key := os.Getenv(env); key != "" {
+			if err := add(env, strings.NewReader(key)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var files []string
+	for _, env := range []string{KeyFileEnv, "SOPS_AGE_KEY_FILE"} {
+		if path := os.Getenv(env); path != "" {
+			files = append(files, path)
+		}
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		files = append(files, filepath.Join(dir, "sops", "age", "keys.txt"))
+	}
+	for _, path := range files {
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = add(path, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return identities, nil
+}
+
+// sopsFormat maps a viper config type to the sops --input-type, or "" when SOPS
+// detection is not supported for it
+func sopsFormat(format string) string {
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		return "yaml"
+	case "json":
+		return "json"
+	default:
+		return ""
+	}
+}
+
+// isSOPS reports whether a YAML or JSON document carries SOPS metadata
+func isSOPS(data []byte) bool {
+	var doc struct {
+		SOPS map[string]any `yaml:"sops"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	_, hasMAC := doc.SOPS["mac"]
+	return hasMAC
+}
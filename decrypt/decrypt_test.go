@@ -0,0 +1,170 @@
+package decrypt
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const plaintext = "app:\n  name: secret-app\n"
+
+func encrypt(t *testing.T, recipient age.Recipient, armored bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var out io.WriteCloser = nopCloser{&buf}
+	if armored {
+		out = armor.NewWriter(&buf)
+	}
+	w, err := age.Encrypt(out, recipient)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	w.Write([]byte(plaintext))
+	w.Close()
+	out.Close()
+	return buf.Bytes()
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestDecryptAge(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity failed: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv(KeyFileEnv, "")
+	t.Setenv(KeyEnv, identity.String())
+
+	for _, armored := range []bool{false, true} {
+		data := encrypt(t, identity.Recipient(), armored)
+		if kind := Detect(data, "yaml"); kind != Age {
+			t.Errorf("Expected age detection (armored=%v), got %q", armored, kind)
+		}
+		got, err := Decrypt(data, "yaml")
+		if err != nil {
+			t.Fatalf("Decrypt failed (armored=%v): %v", armored, err)
+		}
+		if string(got) != plaintext {
+			t.Errorf("Expected %q, got %q", plaintext, got)
+		}
+	}
+}
+
+func TestDecryptAgeWithoutIdentity(t *testing.T) {
+	identity, _ := age.GenerateX25519Identity()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv(KeyFileEnv, "")
+	t.Setenv(KeyEnv, "")
+
+	_, err := Decrypt(encrypt(t, identity.Recipient(), false), "yaml")
+	if err == nil || !strings.Contains(err.Error(), KeyEnv) {
+		t.Errorf("Expected error mentioning %s, got %v", KeyEnv, err)
+	}
+}
+
+func TestDecryptSOPSReadsTempFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake sops binary is a shell script")
+	}
+	// The fake sops records the permissions of its input file and echoes it
+	dir := t.TempDir()
+	script := "#!/bin/sh\nls -l \"$6\" | cut -c1-10 > \"$(dirname \"$0\")/mode\"\necho \"$6\" > \"$(dirname \"$0\")/input\"\ncat \"$6\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "sops"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	out, err := decryptSOPS([]byte(plaintext), "yaml")
+	if err != nil {
+		t.Fatalf("decryptSOPS failed: %v", err)
+	}
+	if string(out) != plaintext {
+		t.Errorf("Expected sops to read the config, got %q", out)
+	}
+	if mode, _ := os.ReadFile(filepath.Join(dir, "mode")); strings.TrimSpace(string(mode)) != "-rw-------" {
+		t.Errorf("Expected an owner-only input file, got %q", mode)
+	}
+	input, _ := os.ReadFile(filepath.Join(dir, "input"))
+	if _, err := os.Stat(strings.TrimSpace(string(input))); !os.IsNotExist(err) {
+		t.Errorf("Expected the input file %s to be removed, got %v", input, err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	sopsYAML := `app:
+  name: ENC[AES256_GCM,data:abc=,iv:def=,tag:ghi=,type:str]
+sops:
+  mac: ENC[AES256_GCM,data:xyz=,iv:def=,tag:ghi=,type:str]
+  version: 3.9.0
+`
+	tests := []struct {
+		name   string
+		data   string
+		format string
+		want   string
+	}{
+		{name: "Plain YAML", data: plaintext, format: "yaml", want: None},
+		{name: "SOPS YAML", data: sopsYAML, format: "yaml", want: SOPS},
+		{name: "SOPS JSON", data: `{"app":{},"sops":{"mac":"ENC[...]"}}`, format: "json", want: SOPS},
+		{name: "SOPS Unsupported Format", data: sopsYAML, format: "properties", want: None},
+		{name: "Sops Key Without Metadata", data: "sops:\n  enabled: true\n", format: "yaml", want: None},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect([]byte(tt.data), tt.format); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	got, err := Decrypt([]byte(plaintext), "yaml")
+	if err != nil || string(got) != plaintext {
+		t.Errorf("Expected plaintext to pass through unchanged, got %q, %v", got, err)
+	}
+}
+
+type staticKeys []age.Identity
+
+func (k staticKeys) Identities() ([]age.Identity, error) { return k, nil }
+
+func TestValueRoundTrip(t *testing.T) {
+	identity, _ := age.GenerateX25519Identity()
+	value, err := EncryptValue("s3cret", identity.Recipient())
+	if err != nil {
+		t.Fatalf("EncryptValue failed: %v", err)
+	}
+	if !IsEncryptedValue(value) {
+		t.Fatalf("Expected %q to carry the %s prefix", value, ValuePrefix)
+	}
+
+	got, err := DecryptValue(value, staticKeys{identity})
+	if err != nil || got != "s3cret" {
+		t.Errorf("Expected s3cret, got %q, %v", got, err)
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	if _, err := DecryptValue(value, staticKeys{other}); err == nil {
+		t.Error("Expected decryption with the wrong identity to fail")
+	}
+	if _, err := DecryptValue("enc:not base64!", staticKeys{identity}); err == nil {
+		t.Error("Expected malformed value to fail")
+	}
+	if got, _ := DecryptValue("plain", staticKeys{}); got != "plain" {
+		t.Errorf("Expected plain value unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,79 @@
+package decrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// ValuePrefix marks an inline encrypted value: "enc:" followed by the
+// base64-encoded age ciphertext of the plaintext
+const ValuePrefix = "enc:"
+
+// KeyProvider supplies the age identities used to decrypt inline values
+type KeyProvider interface {
+	Identities() ([]age.Identity, error)
+}
+
+// EnvKeyProvider reads identities from the same environment variables and key
+// files as encrypted config files (see Identities)
+type EnvKeyProvider struct{}
+
+// Identities implements KeyProvider
+func (EnvKeyProvider) Identities() ([]age.Identity, error) {
+	return Identities()
+}
+
+// IsEncryptedValue reports whether s is an inline encrypted value
+func IsEncryptedValue(s string) bool {
+	return strings.HasPrefix(s, ValuePrefix)
+}
+
+// EncryptValue encrypts plaintext to the recipients and returns it as an
+// inline "enc:" value
+func EncryptValue(plaintext string, recipients ...age.Recipient) (string, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, plaintext); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return ValuePrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecryptValue decrypts an inline "enc:" value with identities from keys.
+// Values without the prefix are returned unchanged.
+func DecryptValue(s string, keys KeyProvider) (string, error) {
+	if !IsEncryptedValue(s) {
+		return s, nil
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(strings.TrimPrefix(s, ValuePrefix)))
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	identities, err := keys.Identities()
+	if err != nil {
+		return "", err
+	}
+	if len(identities) == 0 {
+		return "", fmt.Errorf("encrypted value found but no age identity was found (set %s or %s)", KeyEnv, KeyFileEnv)
+	}
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
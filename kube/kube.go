@@ -0,0 +1,315 @@
+// Package kube reads ConfigMaps and Secrets from the Kubernetes API server and
+// watches them for changes. It talks to the API directly with the pod's service
+// account, so no client library is needed.
+package kube
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Kinds of objects that hold configuration
+const (
+	ConfigMap = "configmaps"
+	Secret    = "secrets"
+)
+
+// serviceAccountDir holds the token, CA certificate, and namespace mounted into
+// every pod
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// ErrNotInCluster is returned by InCluster outside a Kubernetes pod
+var ErrNotInCluster = errors.New("not running in a Kubernetes cluster (KUBERNETES_SERVICE_HOST is not set)")
+
+// ErrNotFound is returned when the object does not exist
+var ErrNotFound = errors.New("not found")
+
+// watchTimeout is how long the server keeps a watch open before the client
+// reconnects
+const watchTimeout = 5 * time.Minute
+
+// emptyWatchDelay is how long Watch waits before reconnecting when the server
+// closed a watch without sending any event, so a stream that closes at once
+// does not turn into a hot loop against the API server
+const emptyWatchDelay = time.Second
+
+// Client is a minimal Kubernetes API client
+type Client struct {
+	// Server is the API server URL
+	Server string
+	// Namespace is used for names without a namespace
+	Namespace string
+	// TokenFile holds the bearer token; it is re-read on every request because
+	// projected service account tokens rotate
+	TokenFile  string
+	HTTPClient *http.Client
+}
+
+// InCluster returns a client for the API server of the cluster the process runs
+// in, authenticated as the pod's service account
+func InCluster() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrNotInCluster
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates in %s/ca.crt", serviceAccountDir)
+	}
+	namespace, err := os.ReadFile(serviceAccountDir + "/namespace")
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Server:    "https://" + net.JoinHostPort(host, port),
+		Namespace: strings.TrimSpace(string(namespace)),
+		TokenFile: serviceAccountDir + "/token",
+		HTTPClient: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}},
+	}, nil
+}
+
+// Object is a ConfigMap or Secret. Secret values are decoded from base64.
+type Object struct {
+	Kind            string
+	Namespace       string
+	Name            string
+	ResourceVersion string
+	Data            map[string]string
+}
+
+// rawObject is the wire format of ConfigMaps and Secrets; Secret data values
+// are base64 strings, which decode into []byte
+type rawObject struct {
+	Metadata struct {
+		Name            string `json:"name"`
+		Namespace       string `json:"namespace"`
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data json.RawMessage `json:"data"`
+}
+
+func (r rawObject) object(kind string) (*Object, error) {
+	obj := &Object{
+		Kind:            kind,
+		Namespace:       r.Metadata.Namespace,
+		Name:            r.Metadata.Name,
+		ResourceVersion: r.Metadata.ResourceVersion,
+		Data:            map[string]string{},
+	}
+	if len(r.Data) == 0 {
+		return obj, nil
+	}
+	if kind != Secret {
+		return obj, json.Unmarshal(r.Data, &obj.Data)
+	}
+	var data map[string][]byte
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		return nil, err
+	}
+	for k, v := range data {
+		obj.Data[k] = string(v)
+	}
+	return obj, nil
+}
+
+// SplitName splits "namespace/name" and falls back to the client namespace
+func (c *Client) SplitName(ref string) (namespace, name string) {
+	if ns, n, ok := strings.Cut(ref, "/"); ok {
+		return ns, n
+	}
+	return c.Namespace, ref
+}
+
+// Get reads a ConfigMap or Secret, named "name" or "namespace/name"
+func (c *Client) Get(ctx context.Context, kind, ref string) (*Object, error) {
+	namespace, name := c.SplitName(ref)
+	resp, err := c.do(ctx, "/api/v1/namespaces/"+url.PathEscape(namespace)+"/"+kind+"/"+url.PathEscape(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var raw rawObject
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decoding %s %s/%s: %w", kind, namespace, name, err)
+	}
+	return raw.object(kind)
+}
+
+// Watch calls onChange whenever the object changes or is deleted, starting after
+// resourceVersion, until ctx is done. It reconnects when the server closes the
+// watch and re-reads the object when the watch has expired. Connection errors are
+// passed to onError and retried with backoff.
+func (c *Client) Watch(ctx context.Context, kind, ref, resourceVersion string, onChange func(), onError func(error)) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		received, err := c.watch(ctx, kind, ref, &resourceVersion, onChange)
+		if errors.Is(err, errExpired) {
+			// Resume from the current version, reporting a change missed meanwhile
+			obj, getErr := c.Get(ctx, kind, ref)
+			switch {
+			case getErr == nil:
+				if obj.ResourceVersion != resourceVersion {
+					resourceVersion = obj.ResourceVersion
+					onChange()
+				}
+				continue
+			case errors.Is(getErr, ErrNotFound):
+				resourceVersion = ""
+				continue
+			}
+			err = getErr
+		}
+		if err == nil || ctx.Err() != nil {
+			backoff = time.Second
+			if !received {
+				select {
+				case <-ctx.Done():
+				case <-time.After(emptyWatchDelay):
+				}
+			}
+			continue
+		}
+		onError(err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// errExpired reports that the watched resource version is too old to resume from
+var errExpired = errors.New("watch expired")
+
+// watchEvent is a single event of a watch stream
+type watchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// watch streams events for one watch connection, updating resourceVersion. It
+// reports whether the server sent any event.
+func (c *Client) watch(ctx context.Context, kind, ref string, resourceVersion *string, onChange func()) (bool, error) {
+	namespace, name := c.SplitName(ref)
+	query := url.Values{
+		"watch":          {"true"},
+		"fieldSelector":  {"metadata.name=" + name},
+		"timeoutSeconds": {fmt.Sprint(int(watchTimeout.Seconds()))},
+	}
+	if *resourceVersion != "" {
+		query.Set("resourceVersion", *resourceVersion)
+	}
+	resp, err := c.do(ctx, "/api/v1/namespaces/"+url.PathEscape(namespace)+"/"+kind, query)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for received := false; ; received = true {
+		var event watchEvent
+		if err := dec.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return received, nil
+			}
+			return received, fmt.Errorf("reading watch of %s %s/%s: %w", kind, namespace, name, err)
+		}
+		switch event.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+			var raw rawObject
+			if err := json.Unmarshal(event.Object, &raw); err != nil {
+				return true, err
+			}
+			if raw.Metadata.ResourceVersion == *resourceVersion {
+				continue
+			}
+			*resourceVersion = raw.Metadata.ResourceVersion
+			onChange()
+		case "ERROR":
+			var status apiStatus
+			json.Unmarshal(event.Object, &status)
+			if status.Code == http.StatusGone {
+				return true, errExpired
+			}
+			return true, fmt.Errorf("watching %s %s/%s: %s", kind, namespace, name, status.Message)
+		}
+	}
+}
+
+// apiStatus is the error body returned by the API server
+type apiStatus struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// do sends an authenticated GET request and returns the response of a 200 reply
+func (c *Client) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := strings.TrimSuffix(c.Server, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.TokenFile != "" {
+		token, err := os.ReadFile(c.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var status apiStatus
+	json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&status)
+	status.Code = resp.StatusCode
+	if status.Message == "" {
+		status.Message = resp.Status
+	}
+	return nil, &statusError{status}
+}
+
+// statusError is an error reply of the API server
+type statusError struct {
+	status apiStatus
+}
+
+func (e *statusError) Error() string {
+	return "kubernetes API: " + e.status.Message
+}
+
+// Is makes a 404 reply match ErrNotFound
+func (e *statusError) Is(target error) bool {
+	return target == ErrNotFound && e.status.Code == http.StatusNotFound
+}
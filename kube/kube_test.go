@@ -0,0 +1,124 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	token := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(token, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return &Client{Server: srv.URL, Namespace: "default", TokenFile: token, HTTPClient: srv.Client()}
+}
+
+func TestGet(t *testing.T) {
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/namespaces/default/configmaps/app":
+			fmt.Fprint(w, `{"metadata":{"name":"app","namespace":"default","resourceVersion":"7"},"data":{"database.host":"db"}}`)
+		case "/api/v1/namespaces/prod/secrets/app":
+			fmt.Fprint(w, `{"metadata":{"name":"app","namespace":"prod","resourceVersion":"9"},"data":{"database.password":"aHVudGVyMg=="}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","message":"configmaps \"missing\" not found","code":404}`)
+		}
+	})
+
+	cm, err := client.Get(context.Background(), ConfigMap, "app")
+	if err != nil {
+		t.Fatalf("Get configmap failed: %v", err)
+	}
+	if cm.ResourceVersion != "7" || cm.Data["database.host"] != "db" {
+		t.Errorf("Unexpected configmap: %+v", cm)
+	}
+
+	secret, err := client.Get(context.Background(), Secret, "prod/app")
+	if err != nil {
+		t.Fatalf("Get secret failed: %v", err)
+	}
+	if secret.Namespace != "prod" || secret.Data["database.password"] != "hunter2" {
+		t.Errorf("Unexpected secret: %+v", secret)
+	}
+
+	if _, err := client.Get(context.Background(), ConfigMap, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path == "/api/v1/namespaces/default/configmaps/app" {
+			fmt.Fprint(w, `{"metadata":{"name":"app","resourceVersion":"3"}}`)
+			return
+		}
+		if q.Get("watch") != "true" || q.Get("fieldSelector") != "metadata.name=app" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch q.Get("resourceVersion") {
+		case "1":
+			// An event for the version already loaded is skipped
+			fmt.Fprintln(w, `{"type":"ADDED","object":{"metadata":{"name":"app","resourceVersion":"1"}}}`)
+			fmt.Fprintln(w, `{"type":"MODIFIED","object":{"metadata":{"name":"app","resourceVersion":"2"}}}`)
+		case "2":
+			// The watch expired; the client re-reads the object at version 3
+			fmt.Fprintln(w, `{"type":"ERROR","object":{"kind":"Status","code":410,"message":"too old resource version"}}`)
+		default:
+			// Hold the connection until the client goes away
+			<-r.Context().Done()
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	errs := make(chan error, 10)
+	go client.Watch(ctx, ConfigMap, "app", "1", func() { changes <- struct{}{} }, func(err error) { errs <- err })
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-changes:
+		case err := <-errs:
+			t.Fatalf("Unexpected watch error: %v", err)
+		case <-ctx.Done():
+			t.Fatalf("Expected 2 change notifications, got %d", i)
+		}
+	}
+	select {
+	case <-changes:
+		t.Error("Expected no further change notifications")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWatchWaitsAfterEmptyStream(t *testing.T) {
+	requests := make(chan struct{}, 100)
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// The stream closes at once without any event
+		requests <- struct{}{}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), emptyWatchDelay/2)
+	defer cancel()
+	client.Watch(ctx, ConfigMap, "app", "1", func() {}, func(err error) { t.Errorf("Unexpected watch error: %v", err) })
+	if n := len(requests); n != 1 {
+		t.Errorf("Expected one watch request before the reconnect delay, got %d", n)
+	}
+}
@@ -0,0 +1,124 @@
+// Package metrics exposes Prometheus metrics for the configuration subsystem.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "myapp"
+
+// Recorder holds the configuration metrics. A nil *Recorder ignores all
+// observations, so callers don't need to check whether metrics are enabled.
+type Recorder struct {
+	registry *prometheus.Registry
+
+	reloadAttempts   prometheus.Counter
+	reloadFailures   prometheus.Counter
+	validationErrors *prometheus.CounterVec
+	lastSuccess      prometheus.Gauge
+	configInfo       *prometheus.GaugeVec
+	validationTime   *prometheus.HistogramVec
+}
+
+// New creates a Recorder with its own registry, including the standard Go and
+// process collectors
+func New() *Recorder {
+	r := &Recorder{
+		registry: prometheus.NewRegistry(),
+		reloadAttempts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "config",
+			Name:      "reload_attempts_total",
+			Help:      "Number of configuration reload attempts.",
+		}),
+		reloadFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "config",
+			Name:      "reload_failures_total",
+			Help:      "Number of configuration reloads rejected because loading or validation failed.",
+		}),
+		validationErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "config",
+			Name:      "validation_errors_total",
+			Help:      "Number of validation errors, by configuration key.",
+		}, []string{"field"}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "config",
+			Name:      "last_successful_load_timestamp_seconds",
+			Help:      "Unix time of the last successful configuration load.",
+		}),
+		configInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "config",
+			Name:      "info",
+			Help:      "Always 1; the hash label identifies the active configuration.",
+		}, []string{"hash"}),
+		validationTime: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "config",
+			Name:      "validation_duration_seconds",
+			Help:      "Time spent validating configurations, by mode: full or incremental.",
+			Buckets:   prometheus.ExponentialBuckets(0.00005, 4, 8),
+		}, []string{"mode"}),
+	}
+	r.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		r.reloadAttempts,
+		r.reloadFailures,
+		r.validationErrors,
+		r.lastSuccess,
+		r.configInfo,
+		r.validationTime,
+	)
+	return r
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (r *Recorder) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveLoad records a configuration load. On success hash identifies the new
+// configuration; on failure invalidKeys lists the keys that failed validation.
+func (r *Recorder) ObserveLoad(ok bool, hash string, invalidKeys []string) {
+	if r == nil {
+		return
+	}
+	for _, key := range invalidKeys {
+		r.validationErrors.WithLabelValues(key).Inc()
+	}
+	if !ok {
+		return
+	}
+	r.lastSuccess.Set(float64(time.Now().Unix()))
+	r.configInfo.Reset()
+	r.configInfo.WithLabelValues(hash).Set(1)
+}
+
+// ObserveReload records a reload attempt and then the load itself
+func (r *Recorder) ObserveReload(ok bool, hash string, invalidKeys []string) {
+	if r == nil {
+		return
+	}
+	r.reloadAttempts.Inc()
+	if !ok {
+		r.reloadFailures.Inc()
+	}
+	r.ObserveLoad(ok, hash, invalidKeys)
+}
+
+// ObserveValidation records how long a full or incremental validation took
+func (r *Recorder) ObserveValidation(mode string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.validationTime.WithLabelValues(mode).Observe(d.Seconds())
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrape returns the metrics exposition of r
+func scrape(t *testing.T, r *Recorder) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	return rec.Body.String()
+}
+
+func TestNilRecorderIgnoresObservations(t *testing.T) {
+	var r *Recorder
+	r.ObserveLoad(true, "abc", nil)
+	r.ObserveReload(false, "", []string{"server.port"})
+	r.ObserveValidation("full", time.Millisecond)
+}
+
+func TestObserveReload(t *testing.T) {
+	r := New()
+	r.ObserveLoad(true, "first", nil)
+	r.ObserveReload(false, "", []string{"server.port"})
+	r.ObserveReload(true, "second", nil)
+
+	out := scrape(t, r)
+	for _, want := range []string{
+		"myapp_config_reload_attempts_total 2\n",
+		"myapp_config_reload_failures_total 1\n",
+		`myapp_config_validation_errors_total{field="server.port"} 1` + "\n",
+		`myapp_config_info{hash="second"} 1` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in metrics:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `hash="first"`) {
+		t.Error("Expected the info metric to drop the replaced configuration")
+	}
+}